@@ -0,0 +1,103 @@
+package kitchen
+
+import (
+	"context"
+	"sync"
+
+	"restaurant-system/internal/kitchen/infrastructure/rmq"
+	"restaurant-system/internal/kitchen/model"
+	"restaurant-system/internal/kitchen/service"
+	"restaurant-system/pkg/logger"
+)
+
+// intakeControl pauses and resumes consuming while orders already on the
+// stove keep cooking.
+type intakeControl struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{}
+	cancel  context.CancelFunc
+}
+
+// next returns the context for the next round of consuming, or, while the
+// worker is paused, a channel that is closed on resume.
+func (c *intakeControl) next(parent context.Context) (context.Context, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		return nil, c.resumed
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	c.cancel = cancel
+	return ctx, nil
+}
+
+func (c *intakeControl) pause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		return false
+	}
+	c.paused = true
+	c.resumed = make(chan struct{})
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return true
+}
+
+func (c *intakeControl) resume() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused {
+		return false
+	}
+	c.paused = false
+	close(c.resumed)
+	return true
+}
+
+func handleCommands(commands <-chan *rmq.ControlMessage, intake *intakeControl, kitchenService *service.KitchenService, worker *model.Worker, drainRequested chan<- struct{}, rid string) {
+	drained := false
+
+	for command := range commands {
+		details := map[string]interface{}{
+			"worker_name": worker.Name,
+			"command":     command.Command,
+			"issued_by":   command.IssuedBy,
+		}
+
+		var status string
+		switch command.Command {
+		case rmq.CommandPause:
+			if !intake.pause() {
+				continue
+			}
+			status = model.WorkerPaused
+		case rmq.CommandResume:
+			if !intake.resume() {
+				continue
+			}
+			status = model.WorkerOnline
+		case rmq.CommandDrain, rmq.CommandDrainLegacy:
+			if !drained {
+				drained = true
+				close(drainRequested)
+			}
+			continue
+		default:
+			logger.Log(logger.ERROR, "kitchen-worker", "unknown_command", "ignoring unknown control command", rid, details, nil)
+			continue
+		}
+
+		logger.Log(logger.INFO, "kitchen-worker", "worker_"+status, "control command applied", rid, details, nil)
+
+		if err := kitchenService.UpdateWorkerStatus(context.Background(), worker.ID, status); err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "worker_update_failed", "failed to update worker status", rid, details, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package kitchen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"restaurant-system/internal/kitchen/infrastructure/rmq"
+	"restaurant-system/internal/kitchen/model"
+	"restaurant-system/internal/kitchen/service"
+	"restaurant-system/pkg/logger"
+)
+
+// job is a delivery from any of the worker's queues, bound to the handler
+// and the consumer that has to ack it.
+type job struct {
+	kind         string
+	order        *rmq.OrderMessage
+	failedAction string
+	details      map[string]interface{}
+	process      func(ctx context.Context) error
+	ack          func() error
+	nack         func(requeue bool) error
+	retry        func(ctx context.Context, cause error) (bool, error)
+}
+
+// orderJobs acks order messages superseded by a higher-priority copy as
+// they arrive, so they take neither a cooking slot nor a place in a batch.
+func orderJobs(ctx context.Context, msgs <-chan *rmq.OrderMessage, consumer *rmq.OrderConsumer, kitchenService *service.KitchenService, worker *model.Worker) <-chan job {
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+		for msg := range msgs {
+			if kitchenService.Superseded(ctx, msg) {
+				if err := consumer.AckMessage(msg.DeliveryTag); err != nil {
+					logger.Log(logger.ERROR, "kitchen-worker", "ack_failed", "failed to ack superseded message", "",
+						map[string]interface{}{"order_number": msg.OrderNumber}, err)
+				}
+				continue
+			}
+
+			jobs <- job{
+				kind:         "order",
+				order:        msg,
+				failedAction: "order_processing_failed",
+				details:      map[string]interface{}{"order_number": msg.OrderNumber},
+				process: func(ctx context.Context) error {
+					return kitchenService.ProcessOrder(ctx, worker, msg)
+				},
+				ack: func() error {
+					return consumer.AckMessage(msg.DeliveryTag)
+				},
+				nack: func(requeue bool) error {
+					return consumer.NackMessage(msg.DeliveryTag, requeue)
+				},
+				retry: func(ctx context.Context, cause error) (bool, error) {
+					return consumer.RetryMessage(ctx, msg, cause)
+				},
+			}
+		}
+	}()
+	return jobs
+}
+
+func ticketJobs(msgs <-chan *rmq.TicketMessage, consumer *rmq.TicketConsumer, kitchenService *service.KitchenService, worker *model.Worker) <-chan job {
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+		for msg := range msgs {
+			jobs <- job{
+				kind:         "ticket",
+				failedAction: "ticket_processing_failed",
+				details:      map[string]interface{}{"order_number": msg.OrderNumber, "ticket_id": msg.TicketID, "station": msg.Station},
+				process: func(ctx context.Context) error {
+					return kitchenService.ProcessTicket(ctx, worker, msg)
+				},
+				ack: func() error {
+					return consumer.AckMessage(msg.DeliveryTag)
+				},
+				nack: func(requeue bool) error {
+					return consumer.NackMessage(msg.DeliveryTag, requeue)
+				},
+				retry: func(ctx context.Context, cause error) (bool, error) {
+					return consumer.RetryMessage(ctx, msg, cause)
+				},
+			}
+		}
+	}()
+	return jobs
+}
+
+// collectBatch gathers up to n more order jobs that arrive within window and
+// share an item with first. Jobs that do not are returned separately so that
+// they can be cooked on their own.
+func collectBatch(first job, jobs <-chan job, n int, window time.Duration, stop <-chan struct{}) ([]job, []job) {
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	batch := []job{first}
+	var rest []job
+	for len(batch) <= n {
+		select {
+		case j, ok := <-jobs:
+			if !ok {
+				return batch, rest
+			}
+			if j.order != nil && service.SharesItems(first.order.Items, j.order.Items) {
+				batch = append(batch, j)
+			} else {
+				rest = append(rest, j)
+			}
+		case <-timer.C:
+			return batch, rest
+		case <-stop:
+			return batch, rest
+		}
+	}
+	return batch, rest
+}
+
+func mergeJobs(sources []<-chan job) <-chan job {
+	merged := make(chan job)
+
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source <-chan job) {
+			defer wg.Done()
+			for j := range source {
+				merged <- j
+			}
+		}(source)
+	}
+
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	return merged
+}
@@ -2,14 +2,20 @@ package kitchen
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"restaurant-system/config"
+	"restaurant-system/internal/kitchen/handler"
 	"restaurant-system/internal/kitchen/infrastructure/pg"
 	"restaurant-system/internal/kitchen/infrastructure/rmq"
+	"restaurant-system/internal/kitchen/model"
 	"restaurant-system/internal/kitchen/service"
 	"restaurant-system/pkg/logger"
 	"restaurant-system/pkg/rabbitmq"
@@ -17,7 +23,44 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq *rabbitmq.RabbitMQ, workerName string, orderTypes []string, prefetch int, heartbeatInterval int, rid string) {
+type BumpOptions struct {
+	Mode    string
+	Port    int
+	Timeout time.Duration
+}
+
+// BatchOptions enables batch cooking when Size is above one: the worker
+// waits up to Window for more orders after the first one and cooks them
+// together.
+type BatchOptions struct {
+	Size   int
+	Window time.Duration
+}
+
+func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq *rabbitmq.RabbitMQ, kitchenCfg config.KitchenConfig, workerName string, orderTypes []string, stations []string, prefetch int, cookingSlots int, heartbeatInterval int, bump BumpOptions, batchOpts BatchOptions, drainTimeout time.Duration, rid string) {
+	if cookingSlots < 1 {
+		cookingSlots = 1
+	}
+
+	// Batches need whole orders on a timer: station tickets, dispatching and
+	// manual bumps each complete on their own.
+	batching := batchOpts.Size > 1
+	if batching && (len(stations) > 0 || kitchenCfg.StationRouting || bump.Mode == "manual") {
+		logger.Log(logger.INFO, "kitchen-worker", "batching_disabled", "batch cooking needs auto bump mode without stations", rid,
+			map[string]interface{}{"batch_size": batchOpts.Size, "bump_mode": bump.Mode, "stations": stations}, nil)
+		batching = false
+	}
+
+	wantPrefetch := cookingSlots
+	if batching {
+		wantPrefetch = cookingSlots * batchOpts.Size
+	}
+	if prefetch < wantPrefetch {
+		logger.Log(logger.INFO, "kitchen-worker", "prefetch_adjusted", "prefetch raised to match cooking slots", rid,
+			map[string]interface{}{"prefetch": prefetch, "cooking_slots": cookingSlots, "batch_size": batchOpts.Size}, nil)
+		prefetch = wantPrefetch
+	}
+
 	workerRepo := pg.NewWorkerRepository(pgxPool)
 	orderRepo := pg.NewOrderRepository(pgxPool)
 	statusPublisher, err := rmq.NewStatusPublisher(rabbitmq)
@@ -26,9 +69,27 @@ func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq *rabbitmq.RabbitMQ
 		return
 	}
 
-	kitchenService := service.NewKitchenService(workerRepo, orderRepo, statusPublisher)
+	var bumper *service.Bumper
+	if bump.Mode == "manual" {
+		bumper = service.NewBumper(bump.Timeout)
+	}
+
+	prepTime := service.NewPrepTimeModel(kitchenCfg)
 
-	worker, err := kitchenService.RegisterWorker(ctx, workerName, orderTypes)
+	var ticketStations []string
+	if kitchenCfg.StationRouting {
+		ticketStations = prepTime.Stations()
+	}
+	ticketPublisher, err := rmq.NewTicketPublisher(rabbitmq, ticketStations)
+	if err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "ticket_publisher_init_failed", "failed to initialize ticket publisher", rid, nil, err)
+		return
+	}
+
+	kitchenService := service.NewKitchenService(workerRepo, orderRepo, statusPublisher, prepTime,
+		pg.NewTicketRepository(pgxPool), ticketPublisher, kitchenCfg.StationRouting, bumper)
+
+	worker, err := kitchenService.RegisterWorker(ctx, workerName, orderTypes, stations, cookingSlots)
 	if err != nil {
 		logger.Log(logger.ERROR, "kitchen-worker", "worker_registration_failed", "failed to register worker", rid,
 			map[string]interface{}{"worker_name": workerName}, err)
@@ -37,13 +98,29 @@ func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq *rabbitmq.RabbitMQ
 
 	logger.Log(logger.INFO, "kitchen-worker", "worker_registered", "worker registered successfully", rid,
 		map[string]interface{}{
-			"worker_name":  worker.Name,
-			"worker_type":  worker.Type,
-			"order_types":  orderTypes,
-			"prefetch":     prefetch,
-			"heartbeat_ms": heartbeatInterval * 1000,
+			"worker_name":   worker.Name,
+			"worker_type":   worker.Type,
+			"version":       worker.Version,
+			"order_types":   orderTypes,
+			"stations":      stations,
+			"prefetch":      prefetch,
+			"cooking_slots": cookingSlots,
+			"heartbeat_ms":  heartbeatInterval * 1000,
+			"bump_mode":     bump.Mode,
+			"batching":      batching,
 		}, nil)
 
+	kitchenService.CheckShift(ctx, worker, rid)
+
+	if bumper != nil {
+		bumpServer := startBumpServer(kitchenService, bump.Port, rid)
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			bumpServer.Shutdown(shutdownCtx)
+		}()
+	}
+
 	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
 	go func() {
 		ticker := time.NewTicker(time.Duration(heartbeatInterval) * time.Second)
@@ -62,50 +139,267 @@ func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq *rabbitmq.RabbitMQ
 		}
 	}()
 
-	consumer, err := rmq.NewOrderConsumer(rabbitmq, prefetch, orderTypes)
+	retryPolicy := rmq.NewRetryPolicy(kitchenCfg.Retry)
+
+	// drainCtx stops intake on shutdown; cookCtx cuts off whatever is still
+	// cooking once the drain timeout runs out.
+	drainCtx, startDrain := context.WithCancel(ctx)
+	defer startDrain()
+	cookCtx, stopCooking := context.WithCancel(ctx)
+	defer stopCooking()
+
+	var orderConsumer *rmq.OrderConsumer
+	var ticketConsumer *rmq.TicketConsumer
+
+	// A worker registered only for stations cooks tickets and leaves whole
+	// orders to the order-type workers.
+	if len(stations) == 0 || len(orderTypes) > 0 {
+		orderConsumer, err = rmq.NewOrderConsumer(rabbitmq, prefetch, orderTypes, retryPolicy)
+		if err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "consumer_init_failed", "failed to initialize consumer", rid, nil, err)
+			stopHeartbeat()
+			return
+		}
+	}
+
+	if len(stations) > 0 {
+		ticketConsumer, err = rmq.NewTicketConsumer(rabbitmq, prefetch, stations, retryPolicy)
+		if err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "consumer_init_failed", "failed to initialize ticket consumer", rid, nil, err)
+			stopHeartbeat()
+			return
+		}
+	}
+
+	// startIntake starts the consumers for one round of consuming. Cancelling
+	// ctx stops them and closes the returned channel.
+	startIntake := func(ctx context.Context) (<-chan job, error) {
+		var sources []<-chan job
+
+		if orderConsumer != nil {
+			msgs, err := orderConsumer.ConsumeOrders(ctx)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, orderJobs(ctx, msgs, orderConsumer, kitchenService, worker))
+		}
+
+		if ticketConsumer != nil {
+			tickets, err := ticketConsumer.ConsumeTickets(ctx)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, ticketJobs(tickets, ticketConsumer, kitchenService, worker))
+		}
+
+		return mergeJobs(sources), nil
+	}
+
+	controlConsumer, err := rmq.NewControlConsumer(rabbitmq, worker.Name)
 	if err != nil {
-		logger.Log(logger.ERROR, "kitchen-worker", "consumer_init_failed", "failed to initialize consumer", rid, nil, err)
+		logger.Log(logger.ERROR, "kitchen-worker", "consumer_init_failed", "failed to initialize control consumer", rid, nil, err)
 		stopHeartbeat()
 		return
 	}
 
-	msgs, err := consumer.ConsumeOrders(ctx)
+	commands, err := controlConsumer.ConsumeCommands(drainCtx)
 	if err != nil {
-		logger.Log(logger.ERROR, "kitchen-worker", "consume_failed", "failed to start consuming messages", rid, nil, err)
+		logger.Log(logger.ERROR, "kitchen-worker", "consume_failed", "failed to start consuming control commands", rid, nil, err)
 		stopHeartbeat()
 		return
 	}
 
+	intake := &intakeControl{}
+	drainRequested := make(chan struct{})
+	go handleCommands(commands, intake, kitchenService, worker, drainRequested, rid)
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 
 	go func() {
-		<-sigChan
-		logger.Log(logger.INFO, "kitchen-worker", "shutdown_initiated", "received termination signal", rid, nil, nil)
-		stopHeartbeat()
+		select {
+		case <-sigChan:
+		case <-drainRequested:
+		case <-ctx.Done():
+		}
+		logger.Log(logger.INFO, "kitchen-worker", "shutdown_initiated", "draining worker", rid,
+			map[string]interface{}{"in_flight_orders": kitchenService.InFlight(), "drain_timeout": drainTimeout.String()}, nil)
 
-		if err := kitchenService.MarkWorkerOffline(ctx, worker.ID); err != nil {
-			logger.Log(logger.ERROR, "kitchen-worker", "shutdown_failed", "failed to mark worker offline", rid,
+		if err := kitchenService.UpdateWorkerStatus(context.Background(), worker.ID, model.WorkerDraining); err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "drain_failed", "failed to mark worker draining", rid,
 				map[string]interface{}{"worker_id": worker.ID}, err)
 		}
+		startDrain()
 
-		os.Exit(0)
+		timer := time.NewTimer(drainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			logger.Log(logger.ERROR, "kitchen-worker", "drain_timeout", "drain timeout reached, interrupting in-flight orders", rid,
+				map[string]interface{}{"in_flight_orders": kitchenService.InFlight()}, nil)
+			stopCooking()
+		case <-cookCtx.Done():
+		}
 	}()
 
-	for msg := range msgs {
-		processCtx := context.WithValue(ctx, "request_id", fmt.Sprintf("msg-%d", time.Now().UnixNano()))
+	// settle acks a finished job, or schedules a retry for a failed one.
+	settle := func(j job, err error) {
+		if err == nil {
+			if err := j.ack(); err != nil {
+				logger.Log(logger.ERROR, "kitchen-worker", "ack_failed", "failed to ack message", rid, nil, err)
+			}
+			return
+		}
 
-		if err := kitchenService.ProcessOrder(processCtx, worker, msg); err != nil {
-			logger.Log(logger.ERROR, "kitchen-worker", "order_processing_failed", "failed to process order", rid,
-				map[string]interface{}{"order_number": msg.OrderNumber}, err)
+		logger.Log(logger.ERROR, "kitchen-worker", j.failedAction, "failed to process "+j.kind, rid, j.details, err)
 
-			if err := consumer.NackMessage(msg.DeliveryTag, true); err != nil {
+		// Work cut short by shutdown or by an expired bump hold is not the
+		// message's fault, so it goes straight back without using up an attempt.
+		if cookCtx.Err() != nil || errors.Is(err, service.BumpExpiredError) {
+			if err := j.nack(true); err != nil {
 				logger.Log(logger.ERROR, "kitchen-worker", "nack_failed", "failed to nack message", rid, nil, err)
 			}
-		} else {
-			if err := consumer.AckMessage(msg.DeliveryTag); err != nil {
-				logger.Log(logger.ERROR, "kitchen-worker", "ack_failed", "failed to ack message", rid, nil, err)
+			return
+		}
+
+		retryCtx, cancelRetry := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelRetry()
+
+		deadLettered, retryErr := j.retry(retryCtx, err)
+		if retryErr != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "retry_failed", "failed to schedule retry", rid, j.details, retryErr)
+			if err := j.nack(true); err != nil {
+				logger.Log(logger.ERROR, "kitchen-worker", "nack_failed", "failed to nack message", rid, nil, err)
+			}
+		} else if deadLettered {
+			logger.Log(logger.ERROR, "kitchen-worker", "message_dead_lettered", j.kind+" moved to dead-letter queue", rid, j.details, err)
+		}
+	}
+
+	slots := make(chan struct{}, cookingSlots)
+	var wg sync.WaitGroup
+
+	for drainCtx.Err() == nil {
+		intakeCtx, resumed := intake.next(drainCtx)
+		if intakeCtx == nil {
+			select {
+			case <-resumed:
+			case <-drainCtx.Done():
+			}
+			continue
+		}
+
+		jobs, err := startIntake(intakeCtx)
+		if err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "consume_failed", "failed to start consuming messages", rid, nil, err)
+			startDrain()
+			break
+		}
+
+		// held keeps jobs that arrived during a batch window but did not fit
+		// the batch; they are cooked on their own before taking new ones.
+		var held []job
+		for {
+			var j job
+			single := len(held) > 0
+			if single {
+				j, held = held[0], held[1:]
+			} else {
+				var ok bool
+				if j, ok = <-jobs; !ok {
+					break
+				}
+			}
+
+			acquired := false
+			select {
+			case slots <- struct{}{}:
+				acquired = true
+			case <-intakeCtx.Done():
+			}
+
+			// Deliveries that were already decoded but never started go back
+			// to the queue for another worker when intake stops.
+			if intakeCtx.Err() != nil {
+				if acquired {
+					<-slots
+				}
+				if err := j.nack(true); err != nil {
+					logger.Log(logger.ERROR, "kitchen-worker", "nack_failed", "failed to nack message", rid, j.details, err)
+				}
+				continue
+			}
+
+			batch := []job{j}
+			if batching && !single && j.order != nil {
+				var rest []job
+				batch, rest = collectBatch(j, jobs, batchOpts.Size-1, batchOpts.Window, intakeCtx.Done())
+				held = append(held, rest...)
 			}
+
+			wg.Add(1)
+
+			go func(batch []job) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				processCtx, cancel := context.WithCancel(context.WithValue(cookCtx, "request_id", fmt.Sprintf("msg-%d", time.Now().UnixNano())))
+				defer cancel()
+
+				if len(batch) == 1 {
+					settle(batch[0], batch[0].process(processCtx))
+					return
+				}
+
+				orderMsgs := make([]*rmq.OrderMessage, len(batch))
+				for i, j := range batch {
+					orderMsgs[i] = j.order
+				}
+				errs := kitchenService.ProcessBatch(processCtx, worker, orderMsgs)
+				for i, j := range batch {
+					settle(j, errs[i])
+				}
+			}(batch)
 		}
 	}
+
+	wg.Wait()
+	stopCooking()
+	stopHeartbeat()
+
+	offlineCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := kitchenService.MarkWorkerOffline(offlineCtx, worker.ID); err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "shutdown_failed", "failed to mark worker offline", rid,
+			map[string]interface{}{"worker_id": worker.ID}, err)
+	}
+
+	logger.Log(logger.INFO, "kitchen-worker", "worker_stopped", "worker drained and stopped", rid,
+		map[string]interface{}{"worker_name": worker.Name}, nil)
+}
+
+func startBumpServer(kitchenService *service.KitchenService, port int, rid string) *http.Server {
+	bumpHandler := handler.NewBumpHandler(kitchenService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /bump", bumpHandler.GetPending)
+	mux.HandleFunc("POST /bump/{orderNumber}", bumpHandler.Bump)
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: mux,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Log(logger.ERROR, "kitchen-worker", "http_server_failed", "bump API failed", rid,
+				map[string]interface{}{"port": port}, err)
+		}
+	}()
+
+	logger.Log(logger.INFO, "kitchen-worker", "bump_api_started", "bump API started", rid,
+		map[string]interface{}{"port": port}, nil)
+
+	return server
 }
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"time"
 
+	"restaurant-system/config"
 	"restaurant-system/internal/order/handler"
 	"restaurant-system/internal/order/infrastructure/pg"
 	"restaurant-system/internal/order/infrastructure/rmq"
+	"restaurant-system/internal/order/model"
 	"restaurant-system/internal/order/service"
 	"restaurant-system/pkg/logger"
 	"restaurant-system/pkg/rabbitmq"
@@ -16,7 +18,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func Run(ctx context.Context, dbPool *pgxpool.Pool, rmqClient *rabbitmq.RabbitMQ, port int, maxConcurrent int, requestID string) {
+func Run(ctx context.Context, dbPool *pgxpool.Pool, rmqClient *rabbitmq.RabbitMQ, admission config.AdmissionConfig, slots config.PickupSlotsConfig, loyalty config.LoyaltyConfig, reaper config.ReaperConfig, aging config.AgingConfig, port int, maxConcurrent int, requestID string) {
 	orderRepo := pg.NewOrderRepository(dbPool)
 	orderPublisher, err := rmq.NewOrderPublisher(rmqClient)
 	if err != nil {
@@ -24,9 +26,23 @@ func Run(ctx context.Context, dbPool *pgxpool.Pool, rmqClient *rabbitmq.RabbitMQ
 		return
 	}
 
-	orderService := service.NewOrderService(orderRepo, orderPublisher)
+	backlogInspector := rmq.NewBacklogInspector(rmqClient, admission.Queues)
+
+	orderService := service.NewOrderService(orderRepo, orderPublisher, backlogInspector, admission, slots, loyalty)
 	orderHandler := handler.NewOrderHandler(orderService)
 
+	if admission.Enabled && model.AdmissionMode(admission.Mode) == model.AdmissionWaitlist {
+		go runWaitlist(ctx, orderService, admission.WaitlistPollSeconds, requestID)
+	}
+
+	if reaper.Enabled {
+		go runReaper(ctx, orderService, reaper, requestID)
+	}
+
+	if aging.Enabled {
+		go runAging(ctx, orderService, aging, requestID)
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /orders", func(w http.ResponseWriter, r *http.Request) {
 		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
@@ -41,6 +57,43 @@ func Run(ctx context.Context, dbPool *pgxpool.Pool, rmqClient *rabbitmq.RabbitMQ
 		orderHandler.GetOrderHandler(w, r.WithContext(ctx))
 	})
 
+	mux.HandleFunc("POST /orders/{orderNumber}/escalate", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.EscalateOrderHandler(w, r.WithContext(ctx))
+	})
+	mux.HandleFunc("POST /orders/{orderNumber}/complete", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.ChangeStatusHandler(w, r.WithContext(ctx), model.StatusCompleted)
+	})
+	mux.HandleFunc("POST /orders/{orderNumber}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.ChangeStatusHandler(w, r.WithContext(ctx), model.StatusCancelled)
+	})
+	mux.HandleFunc("POST /orders/{orderNumber}/refund", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.ChangeStatusHandler(w, r.WithContext(ctx), model.StatusRefunded)
+	})
+	mux.HandleFunc("POST /orders/{orderNumber}/feedback", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.SubmitFeedbackHandler(w, r.WithContext(ctx))
+	})
+	mux.HandleFunc("GET /loyalty/{loyaltyID}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.GetLoyaltyAccountHandler(w, r.WithContext(ctx))
+	})
+	mux.HandleFunc("GET /slots", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.GetSlotsHandler(w, r.WithContext(ctx))
+	})
+	mux.HandleFunc("GET /inventory", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.GetInventoryHandler(w, r.WithContext(ctx))
+	})
+	mux.HandleFunc("POST /inventory/{ingredient}/restock", func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "request_id", fmt.Sprintf("req-%d", time.Now().UnixNano()))
+		orderHandler.RestockHandler(w, r.WithContext(ctx))
+	})
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: mux,
@@ -67,3 +120,86 @@ func Run(ctx context.Context, dbPool *pgxpool.Pool, rmqClient *rabbitmq.RabbitMQ
 
 	logger.Log(logger.INFO, "order-service", "service_stopped", "Order Service stopped", requestID, nil, nil)
 }
+
+func runWaitlist(ctx context.Context, orderService *service.OrderService, pollSeconds int, requestID string) {
+	if pollSeconds <= 0 {
+		pollSeconds = 10
+	}
+	ticker := time.NewTicker(time.Duration(pollSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := orderService.ReleaseWaitlist(ctx)
+			if err != nil {
+				logger.Log(logger.ERROR, "order-service", "waitlist_poll_failed", "failed to release waitlisted orders", requestID, nil, err)
+				continue
+			}
+			if released > 0 {
+				logger.Log(logger.INFO, "order-service", "waitlist_released", "released waitlisted orders", requestID,
+					map[string]interface{}{"released": released}, nil)
+			}
+		}
+	}
+}
+
+func runReaper(ctx context.Context, orderService *service.OrderService, reaper config.ReaperConfig, requestID string) {
+	interval := time.Duration(reaper.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	staleAfter := time.Duration(reaper.StaleAfterSeconds) * time.Second
+	if staleAfter <= 0 {
+		staleAfter = 90 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			recovered, err := orderService.ReapStaleWorkers(ctx, staleAfter)
+			if err != nil {
+				logger.Log(logger.ERROR, "order-service", "reaper_failed", "failed to reap stale workers", requestID, nil, err)
+				continue
+			}
+			if recovered > 0 {
+				logger.Log(logger.INFO, "order-service", "orders_recovered", "recovered orders and tickets from stale workers", requestID,
+					map[string]interface{}{"recovered": recovered}, nil)
+			}
+		}
+	}
+}
+
+func runAging(ctx context.Context, orderService *service.OrderService, aging config.AgingConfig, requestID string) {
+	interval := time.Duration(aging.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			aged, err := orderService.AgeOrders(ctx, aging.Steps)
+			if err != nil {
+				logger.Log(logger.ERROR, "order-service", "aging_failed", "failed to age waiting orders", requestID, nil, err)
+				continue
+			}
+			if aged > 0 {
+				logger.Log(logger.INFO, "order-service", "orders_aged", "raised priority of waiting orders", requestID,
+					map[string]interface{}{"aged": aged}, nil)
+			}
+		}
+	}
+}
@@ -4,17 +4,52 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"restaurant-system/config"
+	kitchenrmq "restaurant-system/internal/kitchen/infrastructure/rmq"
+	kitchenservice "restaurant-system/internal/kitchen/service"
 	"restaurant-system/internal/tracking/handler"
+	"restaurant-system/internal/tracking/infrastructure/rmq"
 	"restaurant-system/internal/tracking/service"
 	"restaurant-system/pkg/logger"
+	"restaurant-system/pkg/rabbitmq"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq interface{}, port int, rid string) {
-	trackingService := service.NewTrackingService(pgxPool)
+func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq *rabbitmq.RabbitMQ, kitchenCfg config.KitchenConfig, port int, rid string) {
+	controlPublisher, err := rmq.NewControlPublisher(rabbitmq)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "control_publisher_init_failed", "failed to initialize control publisher", rid, nil, err)
+		return
+	}
+
+	// The backlog follows the kitchen's own routing; station queues only
+	// exist when orders are split into station tickets.
+	var stations []string
+	if kitchenCfg.StationRouting {
+		stations = kitchenservice.NewPrepTimeModel(kitchenCfg).Stations()
+	}
+
+	trackingService := service.NewTrackingService(pgxPool, rmq.NewDeadLetterQueue(rabbitmq), controlPublisher,
+		rmq.NewQueueInspector(rabbitmq, kitchenrmq.KitchenQueues(stations)))
+
+	statusConsumer, err := rmq.NewStatusConsumer(rabbitmq)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "status_consumer_init_failed", "failed to initialize status consumer", rid, nil, err)
+		return
+	}
+	defer statusConsumer.Close()
+
+	updates, err := statusConsumer.Consume(ctx)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "status_consumer_init_failed", "failed to consume status updates", rid, nil, err)
+		return
+	}
+	go trackingService.RunEvents(updates)
+
 	trackingHandler := handler.NewTrackingHandler(trackingService)
 
 	mux := http.NewServeMux()
@@ -33,6 +68,9 @@ func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq interface{}, port
 			} else if len(path) > len("/history") && path[len(path)-len("/history"):] == "/history" {
 				orderNumber := path[:len(path)-len("/history")]
 				trackingHandler.GetOrderHistory(w, r, orderNumber)
+			} else if len(path) > len("/events") && path[len(path)-len("/events"):] == "/events" {
+				orderNumber := path[:len(path)-len("/events")]
+				trackingHandler.StreamOrderEvents(w, r, orderNumber)
 			} else {
 				http.Error(w, "Not found", http.StatusNotFound)
 			}
@@ -49,6 +87,92 @@ func Run(ctx context.Context, pgxPool *pgxpool.Pool, rabbitmq interface{}, port
 		}
 	})
 
+	mux.HandleFunc("/workers/staffing", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			trackingHandler.GetStaffing(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// POST /workers/{name}/pause | resume | drain-and-exit (or drain)
+	// GET, POST /workers/{name}/shifts
+	// DELETE /workers/{name}/shifts/{id}
+	mux.HandleFunc("/workers/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path[len("/workers/"):], "/"), "/")
+		if len(parts) < 2 || parts[0] == "" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		switch {
+		case len(parts) == 2 && parts[1] == "shifts":
+			if r.Method == http.MethodGet {
+				trackingHandler.GetShifts(w, r, parts[0])
+			} else if r.Method == http.MethodPost {
+				trackingHandler.CreateShift(w, r, parts[0])
+			} else {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		case len(parts) == 3 && parts[1] == "shifts":
+			if r.Method == http.MethodDelete {
+				trackingHandler.DeleteShift(w, r, parts[0], parts[2])
+			} else {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		case len(parts) == 2:
+			if r.Method == http.MethodPost {
+				trackingHandler.SendWorkerCommand(w, r, parts[0], parts[1])
+			} else {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	})
+
+	mux.HandleFunc("/kitchen/dashboard", trackingHandler.KitchenDashboard)
+
+	mux.HandleFunc("/kitchen/backlog", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			trackingHandler.GetKitchenBacklog(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/feedback/summary", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			trackingHandler.GetFeedbackSummary(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/analytics/stages", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			trackingHandler.GetStageAnalytics(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/dead-letters", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			trackingHandler.GetDeadLetters(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/admin/dead-letters/replay", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			trackingHandler.ReplayDeadLetters(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: mux,
@@ -1,8 +1,14 @@
 package config
 
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	RabbitMQ RabbitMQConfig `yaml:"rabbitmq"`
+	Database    DatabaseConfig    `yaml:"database"`
+	RabbitMQ    RabbitMQConfig    `yaml:"rabbitmq"`
+	Admission   AdmissionConfig   `yaml:"admission"`
+	PickupSlots PickupSlotsConfig `yaml:"pickup_slots"`
+	Loyalty     LoyaltyConfig     `yaml:"loyalty"`
+	Reaper      ReaperConfig      `yaml:"reaper"`
+	Aging       AgingConfig       `yaml:"aging"`
+	Kitchen     KitchenConfig     `yaml:"kitchen"`
 }
 
 type DatabaseConfig struct {
@@ -19,3 +25,74 @@ type RabbitMQConfig struct {
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
 }
+
+type AdmissionConfig struct {
+	Enabled               bool     `yaml:"enabled"`
+	Mode                  string   `yaml:"mode"`
+	OrdersPerWorker       int      `yaml:"orders_per_worker"`
+	BaseETAMinutes        int      `yaml:"base_eta_minutes"`
+	MinutesPerQueuedOrder int      `yaml:"minutes_per_queued_order"`
+	WaitlistPollSeconds   int      `yaml:"waitlist_poll_seconds"`
+	Queues                []string `yaml:"queues"`
+}
+
+type PickupSlotsConfig struct {
+	DurationMinutes int    `yaml:"duration_minutes"`
+	OpenTime        string `yaml:"open_time"`
+	CloseTime       string `yaml:"close_time"`
+	MaxOrders       int    `yaml:"max_orders"`
+	MaxItems        int    `yaml:"max_items"`
+}
+
+type LoyaltyConfig struct {
+	Enabled          bool              `yaml:"enabled"`
+	PointValue       float64           `yaml:"point_value"`
+	MaxRedeemPercent float64           `yaml:"max_redeem_percent"`
+	EarnRules        []LoyaltyEarnRule `yaml:"earn_rules"`
+}
+
+type LoyaltyEarnRule struct {
+	OrderType     string  `yaml:"order_type"`
+	MinTotal      float64 `yaml:"min_total"`
+	PointsPerUnit float64 `yaml:"points_per_unit"`
+	BonusPoints   int     `yaml:"bonus_points"`
+}
+
+type KitchenConfig struct {
+	StationRouting bool                      `yaml:"station_routing"`
+	Retry          RetryConfig               `yaml:"retry"`
+	DefaultItem    ItemPrepConfig            `yaml:"default_item"`
+	Items          map[string]ItemPrepConfig `yaml:"items"`
+}
+
+type ItemPrepConfig struct {
+	BaseSeconds    float64 `yaml:"base_seconds"`
+	PerUnitSeconds float64 `yaml:"per_unit_seconds"`
+	Parallelism    int     `yaml:"parallelism"`
+	Station        string  `yaml:"station"`
+}
+
+type RetryConfig struct {
+	MaxAttempts      int `yaml:"max_attempts"`
+	BaseDelaySeconds int `yaml:"base_delay_seconds"`
+	MaxDelaySeconds  int `yaml:"max_delay_seconds"`
+}
+
+type ReaperConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	IntervalSeconds   int  `yaml:"interval_seconds"`
+	StaleAfterSeconds int  `yaml:"stale_after_seconds"`
+}
+
+type AgingConfig struct {
+	Enabled         bool        `yaml:"enabled"`
+	IntervalSeconds int         `yaml:"interval_seconds"`
+	Steps           []AgingStep `yaml:"steps"`
+}
+
+// AgingStep raises an order that has waited at least AfterMinutes to
+// Priority, unless it is already higher.
+type AgingStep struct {
+	AfterMinutes int `yaml:"after_minutes"`
+	Priority     int `yaml:"priority"`
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"restaurant-system/internal/kitchen/service"
+	"restaurant-system/pkg/logger"
+)
+
+type BumpHandler struct {
+	service *service.KitchenService
+}
+
+func NewBumpHandler(s *service.KitchenService) *BumpHandler {
+	return &BumpHandler{service: s}
+}
+
+func (h *BumpHandler) GetPending(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(h.service.PendingBumps())
+	if err != nil {
+		return
+	}
+}
+
+func (h *BumpHandler) Bump(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	key := r.PathValue("orderNumber")
+	if station := r.URL.Query().Get("station"); station != "" {
+		key += "/" + station
+	}
+
+	if err := h.service.Bump(key); err != nil {
+		if errors.Is(err, service.NotPendingError) {
+			http.Error(w, "Order is not waiting to be bumped", http.StatusNotFound)
+			return
+		}
+		logger.Log(logger.ERROR, "kitchen-worker", "bump_failed", "failed to bump order", rid,
+			map[string]interface{}{"key": key}, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "order_bumped", "order bumped by staff", rid,
+		map[string]interface{}{"key": key}, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(map[string]interface{}{"key": key, "bumped": true})
+	if err != nil {
+		return
+	}
+}
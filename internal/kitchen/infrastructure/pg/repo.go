@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"restaurant-system/internal/kitchen/model"
+	"restaurant-system/pkg/orderstatus"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -18,43 +19,76 @@ func NewWorkerRepository(db *pgxpool.Pool) *WorkerRepository {
 	return &WorkerRepository{db: db}
 }
 
-func (r *WorkerRepository) CreateOrUpdateWorker(ctx context.Context, name string, workerType string, orderTypes []string) (*model.Worker, error) {
+func (r *WorkerRepository) CreateOrUpdateWorker(ctx context.Context, w *model.Worker) (*model.Worker, error) {
 	query := `
-        INSERT INTO workers (name, type, status, last_seen, orders_processed)
-        VALUES ($1, $2, 'online', NOW(), 0)
+        INSERT INTO workers (name, type, status, last_seen, started_at, orders_processed, order_types, stations, max_concurrency, version)
+        VALUES ($1, $2, 'online', NOW(), NOW(), 0, $3, $4, $5, $6)
         ON CONFLICT (name) 
         DO UPDATE SET 
             type = EXCLUDED.type,
             status = 'online',
-            last_seen = NOW()
-        RETURNING id, name, type, status, last_seen, orders_processed
+            last_seen = NOW(),
+            started_at = NOW(),
+            order_types = EXCLUDED.order_types,
+            stations = EXCLUDED.stations,
+            max_concurrency = EXCLUDED.max_concurrency,
+            version = EXCLUDED.version
+        RETURNING id, name, type, status, last_seen, orders_processed, order_types, stations, max_concurrency, version
     `
 
+	orderTypes := w.OrderTypes
+	if orderTypes == nil {
+		orderTypes = []string{}
+	}
+	stations := w.Stations
+	if stations == nil {
+		stations = []string{}
+	}
+
 	var worker model.Worker
-	err := r.db.QueryRow(ctx, query, name, workerType).Scan(
+	var version *string
+	err := r.db.QueryRow(ctx, query, w.Name, w.Type, orderTypes, stations, w.MaxConcurrency, w.Version).Scan(
 		&worker.ID,
 		&worker.Name,
 		&worker.Type,
 		&worker.Status,
 		&worker.LastSeen,
 		&worker.OrdersProcessed,
+		&worker.OrderTypes,
+		&worker.Stations,
+		&worker.MaxConcurrency,
+		&version,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create/update worker: %w", err)
 	}
+	if version != nil {
+		worker.Version = *version
+	}
 
-	worker.OrderTypes = orderTypes
 	return &worker, nil
 }
 
-func (r *WorkerRepository) UpdateWorkerHeartbeat(ctx context.Context, id int) error {
-	query := `UPDATE workers SET last_seen = NOW(), status = 'online' WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
+func (r *WorkerRepository) UpdateWorkerHeartbeat(ctx context.Context, id int, inFlight []string) error {
+	query := `
+        UPDATE workers
+        SET last_seen = NOW(),
+            status = CASE WHEN status IN ('paused', 'draining') THEN status ELSE 'online' END,
+            in_flight_orders = $2
+        WHERE id = $1
+    `
+	_, err := r.db.Exec(ctx, query, id, inFlight)
+	return err
+}
+
+func (r *WorkerRepository) UpdateWorkerStatus(ctx context.Context, id int, status string) error {
+	query := `UPDATE workers SET status = $2, last_seen = NOW() WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, id, status)
 	return err
 }
 
 func (r *WorkerRepository) MarkWorkerOffline(ctx context.Context, id int) error {
-	query := `UPDATE workers SET status = 'offline', last_seen = NOW() WHERE id = $1`
+	query := `UPDATE workers SET status = 'offline', last_seen = NOW(), in_flight_orders = '{}' WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
 	return err
 }
@@ -65,6 +99,36 @@ func (r *WorkerRepository) IncrementOrdersProcessed(ctx context.Context, id int)
 	return err
 }
 
+// HasShifts reports whether the worker has any shift on the schedule.
+func (r *WorkerRepository) HasShifts(ctx context.Context, workerName string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM worker_shifts WHERE worker_name = $1)`, workerName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check worker shifts: %w", err)
+	}
+	return exists, nil
+}
+
+// GetCurrentShift returns the worker's shift covering the current time, or nil
+// when the worker is not scheduled right now.
+func (r *WorkerRepository) GetCurrentShift(ctx context.Context, workerName string) (*model.Shift, error) {
+	var shift model.Shift
+	err := r.db.QueryRow(ctx, `
+		SELECT id, starts_at, ends_at, stations
+		FROM worker_shifts
+		WHERE worker_name = $1 AND starts_at <= NOW() AND ends_at > NOW()
+		ORDER BY starts_at
+		LIMIT 1
+	`, workerName).Scan(&shift.ID, &shift.StartsAt, &shift.EndsAt, &shift.Stations)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current shift: %w", err)
+	}
+	return &shift, nil
+}
+
 type OrderRepository struct {
 	db *pgxpool.Pool
 }
@@ -73,10 +137,73 @@ func NewOrderRepository(db *pgxpool.Pool) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderNumber string, status string, processedBy string) error {
-	query := `UPDATE orders SET status = $1, processed_by = $2, updated_at = NOW() WHERE number = $3`
-	_, err := r.db.Exec(ctx, query, status, processedBy, orderNumber)
-	return err
+// TransitionOrder moves an order from the expected status to the next one and
+// logs the change in the same transaction. When the order is no longer in the
+// expected status it returns orderstatus.StaleStatusError.
+func (r *OrderRepository) TransitionOrder(ctx context.Context, orderNumber string, from, to string, changedBy string, notes *string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := transitionOrder(ctx, tx, orderNumber, from, to, changedBy, changedBy, notes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// transitionOrder is the single place that changes an order's status on the
+// kitchen side. It runs in the caller's transaction and returns the order's
+// id. processedBy is stored on the order and credited with the stage timing;
+// an empty processedBy leaves both unattributed, which is how orders cooked
+// by several station workers are recorded.
+func transitionOrder(ctx context.Context, tx pgx.Tx, orderNumber string, from, to string, changedBy, processedBy string, notes *string) (int, error) {
+	if err := orderstatus.Validate(from, to); err != nil {
+		return 0, err
+	}
+
+	var orderID int
+	err := tx.QueryRow(ctx, `
+		UPDATE orders SET status = $1, processed_by = NULLIF($2, ''), updated_at = NOW()
+		WHERE number = $3 AND status = $4
+		RETURNING id
+	`, to, processedBy, orderNumber, from).Scan(&orderID)
+	if err == pgx.ErrNoRows {
+		var current string
+		if err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE number = $1`, orderNumber).Scan(&current); err != nil {
+			return 0, fmt.Errorf("failed to get order status: %w", err)
+		}
+		return 0, fmt.Errorf("%w: expected %s, found %s", orderstatus.StaleStatusError, from, current)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	if err := recordStage(ctx, tx, orderID, from, to, processedBy); err != nil {
+		return 0, err
+	}
+
+	if to == orderstatus.Cooking {
+		if err := consumeStock(ctx, tx, orderID); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_status_log (order_id, status, changed_by, changed_at, notes)
+		VALUES ($1, $2, $3, NOW(), $4)
+	`, orderID, to, changedBy, notes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create status log: %w", err)
+	}
+
+	return orderID, nil
 }
 
 func (r *OrderRepository) CreateStatusLog(ctx context.Context, orderNumber string, status string, changedBy string, notes *string) error {
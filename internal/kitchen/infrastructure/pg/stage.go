@@ -0,0 +1,44 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"restaurant-system/pkg/orderstatus"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// recordStage stores how long the order spent in the stage that the
+// from -> to transition ends, measured from the latest status log entry for
+// from. A repeated stage, for an order that went back to the queue, replaces
+// the earlier measurement. An empty workerName leaves the stage unattributed.
+func recordStage(ctx context.Context, tx pgx.Tx, orderID int, from, to string, workerName string) error {
+	stage, ok := orderstatus.Stage(from, to)
+	if !ok {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO order_stage_timings (order_id, stage, worker_name, order_type, started_at, ended_at, duration_seconds)
+		SELECT o.id, $2, NULLIF($4, ''), o.type, l.changed_at, NOW(), EXTRACT(EPOCH FROM NOW() - l.changed_at)
+		FROM orders o
+		JOIN LATERAL (
+			SELECT changed_at FROM order_status_log
+			WHERE order_id = o.id AND status = $3
+			ORDER BY changed_at DESC
+			LIMIT 1
+		) l ON true
+		WHERE o.id = $1
+		ON CONFLICT (order_id, stage) DO UPDATE SET
+			worker_name = EXCLUDED.worker_name,
+			started_at = EXCLUDED.started_at,
+			ended_at = EXCLUDED.ended_at,
+			duration_seconds = EXCLUDED.duration_seconds
+	`, orderID, stage, from, workerName)
+	if err != nil {
+		return fmt.Errorf("failed to record stage timing: %w", err)
+	}
+
+	return nil
+}
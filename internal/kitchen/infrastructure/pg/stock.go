@@ -0,0 +1,35 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// consumeStock takes the order's reserved ingredients out of stock once the
+// kitchen starts cooking it. Reservations already consumed, for an order that
+// went back to the queue, are left alone.
+func consumeStock(ctx context.Context, tx pgx.Tx, orderID int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE ingredients i SET
+			stock = i.stock - sr.quantity,
+			reserved = i.reserved - sr.quantity,
+			updated_at = NOW()
+		FROM stock_reservations sr
+		WHERE sr.order_id = $1 AND sr.status = 'reserved' AND i.id = sr.ingredient_id
+	`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to consume stock: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE stock_reservations SET status = 'consumed'
+		WHERE order_id = $1 AND status = 'reserved'
+	`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to consume stock reservations: %w", err)
+	}
+
+	return nil
+}
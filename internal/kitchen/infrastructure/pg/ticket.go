@@ -0,0 +1,149 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"restaurant-system/internal/kitchen/model"
+	"restaurant-system/pkg/orderstatus"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TicketRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTicketRepository(db *pgxpool.Pool) *TicketRepository {
+	return &TicketRepository{db: db}
+}
+
+// SplitOrder claims a received order and stores its station tickets in one
+// transaction. It returns false if the order was already claimed. The order
+// is not attributed to the dispatcher, who does not cook it. Tickets are
+// stored flagged for publishing until ClearPublishPending, so the reaper can
+// publish the ones the dispatcher failed to.
+func (r *TicketRepository) SplitOrder(ctx context.Context, orderNumber string, dispatcher string, tickets []*model.Ticket) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	notes := fmt.Sprintf("split into %d station tickets", len(tickets))
+	orderID, err := transitionOrder(ctx, tx, orderNumber, orderstatus.Received, orderstatus.Cooking, dispatcher, "", &notes)
+	if errors.Is(err, orderstatus.StaleStatusError) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, ticket := range tickets {
+		items, err := json.Marshal(ticket.Items)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal ticket items: %w", err)
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO order_tickets (order_id, station, items, publish_pending)
+			VALUES ($1, $2, $3, true)
+			RETURNING id
+		`, orderID, ticket.Station, items).Scan(&ticket.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to create ticket: %w", err)
+		}
+		ticket.OrderID = orderID
+		ticket.Status = "pending"
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *TicketRepository) ClearPublishPending(ctx context.Context, ticketID int) error {
+	query := `UPDATE order_tickets SET publish_pending = false WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, ticketID); err != nil {
+		return fmt.Errorf("failed to clear ticket publish flag: %w", err)
+	}
+	return nil
+}
+
+// ClaimTicket takes a pending ticket of an order that is still cooking, so
+// tickets of a cancelled order are never cooked.
+func (r *TicketRepository) ClaimTicket(ctx context.Context, ticketID int, workerName string) (bool, error) {
+	query := `
+		UPDATE order_tickets t SET status = 'cooking', processed_by = $1, claimed_at = NOW()
+		WHERE t.id = $2 AND t.status = 'pending'
+		  AND EXISTS (SELECT 1 FROM orders o WHERE o.id = t.order_id AND o.status = 'cooking')
+	`
+	tag, err := r.db.Exec(ctx, query, workerName, ticketID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim ticket: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *TicketRepository) ReleaseTicket(ctx context.Context, ticketID int) error {
+	query := `UPDATE order_tickets SET status = 'pending', processed_by = NULL, claimed_at = NULL WHERE id = $1 AND status = 'cooking'`
+	_, err := r.db.Exec(ctx, query, ticketID)
+	return err
+}
+
+// CompleteTicket marks the ticket done and, if it was the last open ticket,
+// moves the order to ready. It reports whether the order became ready.
+func (r *TicketRepository) CompleteTicket(ctx context.Context, ticketID int, workerName string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID int
+	err = tx.QueryRow(ctx, `
+		UPDATE order_tickets SET status = 'done', processed_by = $1, completed_at = NOW()
+		WHERE id = $2 AND status = 'cooking'
+		RETURNING order_id
+	`, workerName, ticketID).Scan(&orderID)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to complete ticket: %w", err)
+	}
+
+	// Lock the order row so that two stations finishing at once agree on
+	// which of them completes the order.
+	var orderNumber, status string
+	err = tx.QueryRow(ctx, `SELECT number, status FROM orders WHERE id = $1 FOR UPDATE`, orderID).Scan(&orderNumber, &status)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	var open int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM order_tickets WHERE order_id = $1 AND status <> 'done'`, orderID).Scan(&open)
+	if err != nil {
+		return false, fmt.Errorf("failed to count open tickets: %w", err)
+	}
+
+	ready := open == 0 && status == orderstatus.Cooking
+	if ready {
+		notes := "all station tickets done"
+		if _, err := transitionOrder(ctx, tx, orderNumber, orderstatus.Cooking, orderstatus.Ready, workerName, "", &notes); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return ready, nil
+}
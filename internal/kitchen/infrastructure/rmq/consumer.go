@@ -4,25 +4,73 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"slices"
+	"sync"
+	"time"
 
 	"restaurant-system/pkg/rabbitmq"
 
 	"github.com/rabbitmq/amqp091-go"
 )
 
-type OrderConsumer struct {
+// OrderTypes are the order types the order service routes to the kitchen.
+// Each has its own queue, so every order lands in exactly one queue no matter
+// how the workers' order type lists overlap.
+var OrderTypes = []string{"dine_in", "takeout", "delivery"}
+
+// OrderQueueName is the queue holding the orders of one type. It matches the
+// single-type priority queues declared by earlier versions.
+func OrderQueueName(orderType string) string {
+	return "kitchen_" + orderType + "_queue"
+}
+
+// StationQueueName is the queue holding the tickets of one station.
+func StationQueueName(station string) string {
+	return "station_queue_" + station
+}
+
+// KitchenQueues lists every queue kitchen work can wait in: one per order
+// type, one per given station and the dead letter queue.
+func KitchenQueues(stations []string) []string {
+	queues := make([]string, 0, len(OrderTypes)+len(stations)+1)
+	for _, orderType := range OrderTypes {
+		queues = append(queues, OrderQueueName(orderType))
+	}
+	for _, station := range stations {
+		queues = append(queues, StationQueueName(station))
+	}
+	return append(queues, DeadLetterQueue)
+}
+
+// consumer is the part of OrderConsumer and TicketConsumer that does not
+// depend on the message type.
+type consumer struct {
 	channel *amqp091.Channel
-	queue   amqp091.Queue
+	queues  []amqp091.Queue
+	retry   RetryPolicy
+}
+
+func (c *consumer) AckMessage(deliveryTag uint64) error {
+	return c.channel.Ack(deliveryTag, false)
 }
 
-func NewOrderConsumer(rabbitmq *rabbitmq.RabbitMQ, prefetch int, orderTypes []string) (*OrderConsumer, error) {
-	ch := rabbitmq.Channel()
+func (c *consumer) NackMessage(deliveryTag uint64, requeue bool) error {
+	return c.channel.Nack(deliveryTag, false, requeue)
+}
 
-	err := ch.Qos(
+// openConsumerChannel opens a dedicated channel, so a failed ack cannot close
+// the channel the status updates go out on. The prefetch limit is shared by
+// all the queues consumed on it.
+func openConsumerChannel(rabbitmq *rabbitmq.RabbitMQ, prefetch int) (*amqp091.Channel, error) {
+	ch, err := rabbitmq.OpenChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ch.Qos(
 		prefetch,
 		0,
-		false,
+		true,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to set QoS: %w", err)
@@ -41,41 +89,114 @@ func NewOrderConsumer(rabbitmq *rabbitmq.RabbitMQ, prefetch int, orderTypes []st
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
-	queueName := "kitchen_queue"
-	if len(orderTypes) > 0 {
-		queueName = "kitchen_queue_" + strings.Join(orderTypes, "_")
+	return ch, nil
+}
+
+// consume delivers decoded messages from all the queues until ctx is
+// cancelled. Cancelling stops the broker consumers and requeues whatever was
+// prefetched but not yet handed out, including a message the reader never
+// took. Messages that cannot be decoded go straight to the dead-letter queue.
+func consume[M any](ctx context.Context, c *consumer, decode func(queue string, msg amqp091.Delivery) (*M, error)) (<-chan *M, error) {
+	out := make(chan *M, 100)
+
+	var wg sync.WaitGroup
+	for _, queue := range c.queues {
+		tag := fmt.Sprintf("kitchen-%s-%d", queue.Name, time.Now().UnixNano())
+		msgs, err := c.channel.Consume(
+			queue.Name,
+			tag,
+			false,
+			false,
+			false,
+			false,
+			nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to consume messages: %w", err)
+		}
+
+		queueName := queue.Name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					requeueRemaining(c.channel, tag, msgs)
+					return
+				case msg, ok := <-msgs:
+					if !ok {
+						return
+					}
+
+					decoded, err := decode(queueName, msg)
+					if err != nil {
+						if _, err := deadLetter(ctx, c.channel, queueName, msg, err); err != nil {
+							return
+						}
+						continue
+					}
+
+					select {
+					case out <- decoded:
+					case <-ctx.Done():
+						msg.Nack(false, true)
+						requeueRemaining(c.channel, tag, msgs)
+						return
+					}
+				}
+			}
+		}()
 	}
 
-	queue, err := ch.QueueDeclare(
-		queueName,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+type OrderConsumer struct {
+	consumer
+}
+
+// NewOrderConsumer subscribes to the queue of every order type the worker
+// handles, or all of them for a general worker. Workers sharing a queue get
+// its orders round-robin. The prefetch limit is shared by all the queues, so
+// a general worker holds no more unacked orders than a specialised one.
+func NewOrderConsumer(rabbitmq *rabbitmq.RabbitMQ, prefetch int, orderTypes []string, retry RetryPolicy) (*OrderConsumer, error) {
+	if len(orderTypes) == 0 {
+		orderTypes = OrderTypes
+	}
+	for _, orderType := range orderTypes {
+		if !slices.Contains(OrderTypes, orderType) {
+			return nil, fmt.Errorf("unknown order type: %s", orderType)
+		}
+	}
+
+	ch, err := openConsumerChannel(rabbitmq, prefetch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to declare queue: %w", err)
-	}
-
-	if len(orderTypes) > 0 {
-		for _, orderType := range orderTypes {
-			routingKey := "kitchen." + orderType + ".*"
-			err = ch.QueueBind(
-				queue.Name,
-				routingKey,
-				"orders_topic",
-				false,
-				nil,
-			)
-			if err != nil {
-				return nil, fmt.Errorf("failed to bind queue: %w", err)
-			}
+		return nil, err
+	}
+
+	var queues []amqp091.Queue
+	for _, orderType := range orderTypes {
+		queue, err := ch.QueueDeclare(
+			OrderQueueName(orderType),
+			true,
+			false,
+			false,
+			false,
+			amqp091.Table{"x-max-priority": MaxPriority},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to declare queue: %w", err)
 		}
-	} else {
+
 		err = ch.QueueBind(
 			queue.Name,
-			"kitchen.*.*",
+			"kitchen."+orderType+".*",
 			"orders_topic",
 			false,
 			nil,
@@ -83,63 +204,151 @@ func NewOrderConsumer(rabbitmq *rabbitmq.RabbitMQ, prefetch int, orderTypes []st
 		if err != nil {
 			return nil, fmt.Errorf("failed to bind queue: %w", err)
 		}
+
+		if err := declareRetryTopology(ch, queue.Name, retry); err != nil {
+			return nil, err
+		}
+
+		queues = append(queues, queue)
 	}
 
-	return &OrderConsumer{
-		channel: ch,
-		queue:   queue,
-	}, nil
+	return &OrderConsumer{consumer{channel: ch, queues: queues, retry: retry}}, nil
 }
 
+// ConsumeOrders delivers orders from all the worker's queues until ctx is
+// cancelled.
 func (c *OrderConsumer) ConsumeOrders(ctx context.Context) (<-chan *OrderMessage, error) {
-	msgs, err := c.channel.Consume(
-		c.queue.Name,
-		"",
-		false,
-		false,
-		false,
-		false,
-		nil,
-	)
+	return consume(ctx, &c.consumer, func(queue string, msg amqp091.Delivery) (*OrderMessage, error) {
+		var orderMsg OrderMessage
+		if err := json.Unmarshal(msg.Body, &orderMsg); err != nil {
+			return nil, err
+		}
+
+		orderMsg.DeliveryTag = msg.DeliveryTag
+		orderMsg.Queue = queue
+		orderMsg.Attempts = HeaderInt(msg.Headers, AttemptsHeader)
+		orderMsg.Body = msg.Body
+		return &orderMsg, nil
+	})
+}
+
+// RetryMessage schedules a failed order for delayed redelivery, or moves it
+// to the dead-letter queue once it has used up its attempts.
+func (c *OrderConsumer) RetryMessage(ctx context.Context, msg *OrderMessage, cause error) (bool, error) {
+	return retryOrDeadLetter(ctx, c.channel, c.retry, delivery{
+		queue:       msg.Queue,
+		body:        msg.Body,
+		priority:    uint8(msg.Priority),
+		attempts:    msg.Attempts,
+		deliveryTag: msg.DeliveryTag,
+	}, cause)
+}
+
+// deadLetter skips the retries for a message that can never be decoded.
+func deadLetter(ctx context.Context, ch *amqp091.Channel, queue string, msg amqp091.Delivery, cause error) (bool, error) {
+	return retryOrDeadLetter(ctx, ch, RetryPolicy{MaxAttempts: 1}, delivery{
+		queue:       queue,
+		body:        msg.Body,
+		priority:    msg.Priority,
+		attempts:    HeaderInt(msg.Headers, AttemptsHeader),
+		deliveryTag: msg.DeliveryTag,
+	}, cause)
+}
+
+type TicketConsumer struct {
+	consumer
+}
+
+// NewTicketConsumer subscribes to the worker's station queues. The prefetch
+// limit is shared by all the stations.
+func NewTicketConsumer(rabbitmq *rabbitmq.RabbitMQ, prefetch int, stations []string, retry RetryPolicy) (*TicketConsumer, error) {
+	ch, err := openConsumerChannel(rabbitmq, prefetch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to consume messages: %w", err)
+		return nil, err
 	}
 
-	orderMsgs := make(chan *OrderMessage, 100)
+	var queues []amqp091.Queue
+	for _, station := range stations {
+		queue, err := declareStationQueue(ch, station)
+		if err != nil {
+			return nil, err
+		}
 
-	go func() {
-		defer close(orderMsgs)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case msg, ok := <-msgs:
-				if !ok {
-					return
-				}
+		if err := declareRetryTopology(ch, queue.Name, retry); err != nil {
+			return nil, err
+		}
 
-				var orderMsg OrderMessage
-				if err := json.Unmarshal(msg.Body, &orderMsg); err != nil {
-					err := msg.Nack(false, false)
-					if err != nil {
-						return
-					}
-					continue
-				}
+		queues = append(queues, queue)
+	}
 
-				orderMsg.DeliveryTag = msg.DeliveryTag
-				orderMsgs <- &orderMsg
-			}
+	return &TicketConsumer{consumer{channel: ch, queues: queues, retry: retry}}, nil
+}
+
+// ConsumeTickets delivers tickets from all the worker's station queues until
+// ctx is cancelled.
+func (c *TicketConsumer) ConsumeTickets(ctx context.Context) (<-chan *TicketMessage, error) {
+	return consume(ctx, &c.consumer, func(queue string, msg amqp091.Delivery) (*TicketMessage, error) {
+		var ticketMsg TicketMessage
+		if err := json.Unmarshal(msg.Body, &ticketMsg); err != nil {
+			return nil, err
 		}
-	}()
 
-	return orderMsgs, nil
+		ticketMsg.DeliveryTag = msg.DeliveryTag
+		ticketMsg.Queue = queue
+		ticketMsg.Attempts = HeaderInt(msg.Headers, AttemptsHeader)
+		ticketMsg.Body = msg.Body
+		return &ticketMsg, nil
+	})
 }
 
-func (c *OrderConsumer) AckMessage(deliveryTag uint64) error {
-	return c.channel.Ack(deliveryTag, false)
+func (c *TicketConsumer) RetryMessage(ctx context.Context, msg *TicketMessage, cause error) (bool, error) {
+	return retryOrDeadLetter(ctx, c.channel, c.retry, delivery{
+		queue:       msg.Queue,
+		body:        msg.Body,
+		priority:    uint8(msg.Priority),
+		attempts:    msg.Attempts,
+		deliveryTag: msg.DeliveryTag,
+	}, cause)
 }
 
-func (c *OrderConsumer) NackMessage(deliveryTag uint64, requeue bool) error {
-	return c.channel.Nack(deliveryTag, false, requeue)
+// declareStationQueue declares a station's ticket queue and binds it to
+// orders_topic. Ticket publishers and station workers both call it.
+func declareStationQueue(ch *amqp091.Channel, station string) (amqp091.Queue, error) {
+	queue, err := ch.QueueDeclare(
+		StationQueueName(station),
+		true,
+		false,
+		false,
+		false,
+		amqp091.Table{"x-max-priority": MaxPriority},
+	)
+	if err != nil {
+		return amqp091.Queue{}, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	err = ch.QueueBind(
+		queue.Name,
+		"station."+station+".*",
+		"orders_topic",
+		false,
+		nil,
+	)
+	if err != nil {
+		return amqp091.Queue{}, fmt.Errorf("failed to bind queue: %w", err)
+	}
+
+	return queue, nil
+}
+
+// requeueRemaining cancels a consumer and nacks every delivery the broker had
+// already prefetched for it, so other workers can pick them up.
+func requeueRemaining(ch *amqp091.Channel, tag string, msgs <-chan amqp091.Delivery) {
+	if err := ch.Cancel(tag, false); err != nil {
+		return
+	}
+	for msg := range msgs {
+		if err := msg.Nack(false, true); err != nil {
+			return
+		}
+	}
 }
@@ -0,0 +1,110 @@
+package rmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"restaurant-system/pkg/rabbitmq"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+const ControlExchange = "worker_control"
+
+type ControlConsumer struct {
+	channel *amqp091.Channel
+	queue   amqp091.Queue
+}
+
+// NewControlConsumer declares the worker's private control queue. The queue
+// is exclusive, so commands sent while the worker is down are dropped and a
+// second process with the same worker name is refused.
+func NewControlConsumer(rabbitmq *rabbitmq.RabbitMQ, workerName string) (*ControlConsumer, error) {
+	ch := rabbitmq.Channel()
+
+	err := ch.ExchangeDeclare(
+		ControlExchange,
+		"direct",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	queue, err := ch.QueueDeclare(
+		"worker_control_"+workerName,
+		false,
+		true,
+		true,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare control queue: %w", err)
+	}
+
+	err = ch.QueueBind(
+		queue.Name,
+		workerName,
+		ControlExchange,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind control queue: %w", err)
+	}
+
+	return &ControlConsumer{
+		channel: ch,
+		queue:   queue,
+	}, nil
+}
+
+func (c *ControlConsumer) ConsumeCommands(ctx context.Context) (<-chan *ControlMessage, error) {
+	msgs, err := c.channel.Consume(
+		c.queue.Name,
+		"",
+		true,
+		true,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume control messages: %w", err)
+	}
+
+	commands := make(chan *ControlMessage)
+
+	go func() {
+		defer close(commands)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+
+				var command ControlMessage
+				if err := json.Unmarshal(msg.Body, &command); err != nil {
+					continue
+				}
+
+				select {
+				case commands <- &command:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return commands, nil
+}
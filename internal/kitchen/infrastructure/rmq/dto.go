@@ -4,20 +4,40 @@ import (
 	"time"
 )
 
+const MaxPriority = 10
+
 type OrderMessage struct {
-	OrderNumber     string  `json:"order_number"`
-	CustomerName    string  `json:"customer_name"`
-	OrderType       string  `json:"order_type"`
-	TableNumber     *int    `json:"table_number,omitempty"`
-	DeliveryAddress *string `json:"delivery_address,omitempty"`
-	Items           []struct {
-		Name     string  `json:"name"`
-		Quantity int     `json:"quantity"`
-		Price    float64 `json:"price"`
-	} `json:"items"`
-	TotalAmount float64 `json:"total_amount"`
-	Priority    int     `json:"priority"`
-	DeliveryTag uint64  `json:"-"`
+	OrderNumber     string      `json:"order_number"`
+	CustomerName    string      `json:"customer_name"`
+	OrderType       string      `json:"order_type"`
+	TableNumber     *int        `json:"table_number,omitempty"`
+	DeliveryAddress *string     `json:"delivery_address,omitempty"`
+	Items           []OrderItem `json:"items"`
+	TotalAmount     float64     `json:"total_amount"`
+	Priority        int         `json:"priority"`
+	DeliveryTag     uint64      `json:"-"`
+	Queue           string      `json:"-"`
+	Attempts        int         `json:"-"`
+	Body            []byte      `json:"-"`
+}
+
+type OrderItem struct {
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type TicketMessage struct {
+	TicketID    int         `json:"ticket_id"`
+	OrderNumber string      `json:"order_number"`
+	OrderType   string      `json:"order_type"`
+	Station     string      `json:"station"`
+	Items       []OrderItem `json:"items"`
+	Priority    int         `json:"priority"`
+	DeliveryTag uint64      `json:"-"`
+	Queue       string      `json:"-"`
+	Attempts    int         `json:"-"`
+	Body        []byte      `json:"-"`
 }
 
 type StatusUpdateMessage struct {
@@ -29,3 +49,18 @@ type StatusUpdateMessage struct {
 	EstimatedCompletion time.Time `json:"estimated_completion"`
 	DeliveryTag         uint64    `json:"-"`
 }
+
+const (
+	CommandPause  = "pause"
+	CommandResume = "resume"
+	CommandDrain  = "drain-and-exit"
+	// CommandDrainLegacy is sent by tracking services that predate the
+	// drain-and-exit name.
+	CommandDrainLegacy = "drain"
+)
+
+type ControlMessage struct {
+	Command   string    `json:"command"`
+	IssuedBy  string    `json:"issued_by"`
+	Timestamp time.Time `json:"timestamp"`
+}
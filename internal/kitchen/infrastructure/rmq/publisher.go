@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"restaurant-system/pkg/rabbitmq"
 
@@ -55,3 +56,95 @@ func (p *StatusPublisher) PublishStatusUpdate(ctx context.Context, update *Statu
 
 	return nil
 }
+
+// TicketPublisher publishes station tickets on its own channel in confirm
+// mode. Tickets are mandatory, so one that no queue takes comes back as a
+// publish error instead of being dropped by the broker.
+type TicketPublisher struct {
+	channel *amqp091.Channel
+	returns chan amqp091.Return
+	mu      sync.Mutex
+}
+
+// NewTicketPublisher also declares the queue of every station the kitchen
+// routes to, so tickets wait for a station worker that has not started yet.
+func NewTicketPublisher(rabbitmq *rabbitmq.RabbitMQ, stations []string) (*TicketPublisher, error) {
+	ch, err := rabbitmq.OpenChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ch.ExchangeDeclare(
+		"orders_topic",
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	for _, station := range stations {
+		if _, err := declareStationQueue(ch, station); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	return &TicketPublisher{
+		channel: ch,
+		returns: ch.NotifyReturn(make(chan amqp091.Return, 1)),
+	}, nil
+}
+
+// PublishTicket returns once the broker has confirmed the ticket. Publishes
+// are serialised so that a returned ticket is matched with its publish: the
+// broker sends the return before the confirm.
+func (p *TicketPublisher) PublishTicket(ctx context.Context, ticket *TicketMessage) error {
+	body, err := json.Marshal(ticket)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket: %w", err)
+	}
+
+	routingKey := fmt.Sprintf("station.%s.%s", ticket.Station, ticket.OrderType)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	confirm, err := p.channel.PublishWithDeferredConfirmWithContext(ctx,
+		"orders_topic",
+		routingKey,
+		true,
+		false,
+		amqp091.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp091.Persistent,
+			Priority:     uint8(ticket.Priority),
+		})
+	if err != nil {
+		return fmt.Errorf("failed to publish ticket: %w", err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to confirm ticket: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("ticket %d rejected by broker", ticket.TicketID)
+	}
+
+	select {
+	case ret := <-p.returns:
+		return fmt.Errorf("ticket %d unroutable: %s", ticket.TicketID, ret.ReplyText)
+	default:
+	}
+
+	return nil
+}
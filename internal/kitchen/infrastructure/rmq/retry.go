@@ -0,0 +1,192 @@
+package rmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant-system/config"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	DeadLetterExchange = "orders_dlx"
+	DeadLetterQueue    = "kitchen_dead_letter"
+
+	AttemptsHeader      = "x-attempts"
+	LastErrorHeader     = "x-last-error"
+	OriginalQueueHeader = "x-original-queue"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func NewRetryPolicy(cfg config.RetryConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.BaseDelaySeconds) * time.Second,
+		MaxDelay:    time.Duration(cfg.MaxDelaySeconds) * time.Second,
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = time.Second
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+	return policy
+}
+
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// retryQueueName names a delay queue after its TTL. RabbitMQ refuses to
+// redeclare a queue with a different x-message-ttl, so a changed retry policy
+// declares new queues instead of clashing with the old ones.
+func retryQueueName(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%d", queue, delay.Milliseconds())
+}
+
+// declareRetryTopology declares the shared dead-letter queue and one delay
+// queue per distinct retry delay. A delay queue holds messages for its TTL and
+// then dead-letters them straight back to the consumer queue.
+func declareRetryTopology(ch *amqp091.Channel, queue string, policy RetryPolicy) error {
+	err := ch.ExchangeDeclare(
+		DeadLetterExchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+
+	_, err = ch.QueueDeclare(
+		DeadLetterQueue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+
+	err = ch.QueueBind(
+		DeadLetterQueue,
+		"#",
+		DeadLetterExchange,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to bind dead-letter queue: %w", err)
+	}
+
+	declared := make(map[time.Duration]bool)
+	for attempt := 1; attempt < policy.MaxAttempts; attempt++ {
+		delay := policy.Delay(attempt)
+		if declared[delay] {
+			continue
+		}
+		declared[delay] = true
+
+		_, err := ch.QueueDeclare(
+			retryQueueName(queue, delay),
+			true,
+			false,
+			false,
+			false,
+			amqp091.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queue,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type delivery struct {
+	queue       string
+	body        []byte
+	priority    uint8
+	attempts    int
+	deliveryTag uint64
+}
+
+// retryOrDeadLetter acks the failed delivery after publishing a copy either to
+// the delay queue for the next attempt or, once attempts are exhausted, to the dead-letter
+// exchange. It reports whether the message was dead-lettered.
+func retryOrDeadLetter(ctx context.Context, ch *amqp091.Channel, policy RetryPolicy, d delivery, cause error) (bool, error) {
+	attempt := d.attempts + 1
+
+	exchange, routingKey := "", retryQueueName(d.queue, policy.Delay(attempt))
+	deadLettered := attempt >= policy.MaxAttempts
+	if deadLettered {
+		exchange, routingKey = DeadLetterExchange, d.queue
+	}
+
+	err := ch.PublishWithContext(ctx,
+		exchange,
+		routingKey,
+		false,
+		false,
+		amqp091.Publishing{
+			ContentType:  "application/json",
+			Body:         d.body,
+			DeliveryMode: amqp091.Persistent,
+			Priority:     d.priority,
+			Timestamp:    time.Now(),
+			Headers: amqp091.Table{
+				AttemptsHeader:      int32(attempt),
+				LastErrorHeader:     cause.Error(),
+				OriginalQueueHeader: d.queue,
+			},
+		})
+	if err != nil {
+		return false, fmt.Errorf("failed to publish retry: %w", err)
+	}
+
+	if err := ch.Ack(d.deliveryTag, false); err != nil {
+		return deadLettered, fmt.Errorf("failed to ack retried message: %w", err)
+	}
+
+	return deadLettered, nil
+}
+
+func HeaderInt(headers amqp091.Table, key string) int {
+	switch v := headers[key].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case int16:
+		return int(v)
+	case int8:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,69 @@
+package rmq
+
+import (
+	"testing"
+	"time"
+
+	"restaurant-system/config"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := NewRetryPolicy(config.RetryConfig{MaxAttempts: 5, BaseDelaySeconds: 5, MaxDelaySeconds: 30})
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{4, 30 * time.Second},
+		{5, 30 * time.Second},
+		{50, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestNewRetryPolicyDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.RetryConfig
+		want RetryPolicy
+	}{
+		{
+			name: "unset",
+			cfg:  config.RetryConfig{},
+			want: RetryPolicy{MaxAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Second},
+		},
+		{
+			name: "max below base",
+			cfg:  config.RetryConfig{MaxAttempts: 3, BaseDelaySeconds: 10, MaxDelaySeconds: 5},
+			want: RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: 10 * time.Second},
+		},
+		{
+			name: "configured",
+			cfg:  config.RetryConfig{MaxAttempts: 5, BaseDelaySeconds: 5, MaxDelaySeconds: 300},
+			want: RetryPolicy{MaxAttempts: 5, BaseDelay: 5 * time.Second, MaxDelay: 300 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRetryPolicy(tt.cfg); got != tt.want {
+				t.Errorf("NewRetryPolicy(%+v) = %+v, want %+v", tt.cfg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryQueueName(t *testing.T) {
+	if got, want := retryQueueName("kitchen_dine_in_queue", 5*time.Second), "kitchen_dine_in_queue.retry.5000"; got != want {
+		t.Errorf("retryQueueName() = %q, want %q", got, want)
+	}
+}
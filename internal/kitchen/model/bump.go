@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type PendingBump struct {
+	Key            string    `json:"key"`
+	OrderNumber    string    `json:"order_number"`
+	StartedAt      time.Time `json:"started_at"`
+	ElapsedSeconds int       `json:"elapsed_seconds"`
+	Overdue        bool      `json:"overdue"`
+}
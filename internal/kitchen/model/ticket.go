@@ -0,0 +1,16 @@
+package model
+
+type Ticket struct {
+	ID          int          `json:"id"`
+	OrderID     int          `json:"order_id"`
+	OrderNumber string       `json:"order_number"`
+	Station     string       `json:"station"`
+	Items       []TicketItem `json:"items"`
+	Status      string       `json:"status"`
+	ProcessedBy *string      `json:"processed_by,omitempty"`
+}
+
+type TicketItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
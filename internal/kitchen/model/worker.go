@@ -2,6 +2,13 @@ package model
 
 import "time"
 
+const (
+	WorkerOnline   = "online"
+	WorkerPaused   = "paused"
+	WorkerDraining = "draining"
+	WorkerOffline  = "offline"
+)
+
 type Worker struct {
 	ID              int       `json:"id"`
 	Name            string    `json:"name"`
@@ -10,4 +17,16 @@ type Worker struct {
 	LastSeen        time.Time `json:"last_seen"`
 	OrdersProcessed int       `json:"orders_processed"`
 	OrderTypes      []string  `json:"order_types,omitempty"`
+	Stations        []string  `json:"stations,omitempty"`
+	MaxConcurrency  int       `json:"max_concurrency"`
+	Version         string    `json:"version"`
+}
+
+// Shift is a planned working window for a worker. Empty Stations means the
+// worker may cover any station.
+type Shift struct {
+	ID       int       `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Stations []string  `json:"stations,omitempty"`
 }
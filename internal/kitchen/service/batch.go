@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant-system/internal/kitchen/infrastructure/rmq"
+	"restaurant-system/internal/kitchen/model"
+	"restaurant-system/pkg/logger"
+)
+
+// ProcessBatch cooks several orders together. Identical items across the
+// orders are merged, so each shares one base preparation time, and every
+// order is then completed on its own, with its own timing in the status log
+// and its in-flight tracking released as soon as it is done. The returned
+// errors line up with orderMsgs.
+func (s *KitchenService) ProcessBatch(ctx context.Context, worker *model.Worker, orderMsgs []*rmq.OrderMessage) []error {
+	rid := ""
+	if v := ctx.Value("request_id"); v != nil {
+		if str, ok := v.(string); ok {
+			rid = str
+		}
+	}
+
+	errs := make([]error, len(orderMsgs))
+	var batch []*rmq.OrderMessage
+	var indexes []int
+	var items [][]rmq.OrderItem
+	var untrack []func()
+
+	for i, orderMsg := range orderMsgs {
+		if err := s.checkOrderType(worker, orderMsg, rid); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		claimed, err := s.claimOrder(ctx, worker, orderMsg, rid)
+		if err != nil || !claimed {
+			errs[i] = err
+			continue
+		}
+
+		untrack = append(untrack, s.trackOrder(orderMsg.OrderNumber))
+		batch = append(batch, orderMsg)
+		indexes = append(indexes, i)
+		items = append(items, orderMsg.Items)
+	}
+
+	if len(batch) == 0 {
+		return errs
+	}
+
+	orderNumbers := make([]string, len(batch))
+	for i, orderMsg := range batch {
+		orderNumbers[i] = orderMsg.OrderNumber
+	}
+
+	cookingTime := s.prepTime.OrderDuration(s.prepTime.MergeItems(items...))
+	startedAt := time.Now()
+	for _, orderMsg := range batch {
+		s.publishCooking(ctx, worker, orderMsg, startedAt, startedAt.Add(cookingTime), rid)
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "batch_started", "cooking orders as one batch", rid,
+		map[string]interface{}{
+			"order_numbers": orderNumbers,
+			"cooking_time":  cookingTime.String(),
+		}, nil)
+
+	key := "batch-" + batch[0].OrderNumber
+	if err := s.cook(ctx, key, batch[0].OrderNumber, cookingTime, worker, rid); err != nil {
+		for n, orderMsg := range batch {
+			s.releaseOrder(orderMsg.OrderNumber, worker.Name, rid)
+			untrack[n]()
+			errs[indexes[n]] = fmt.Errorf("cooking interrupted: %w", err)
+		}
+		return errs
+	}
+
+	for n, orderMsg := range batch {
+		alone := s.prepTime.OrderDuration(orderMsg.Items)
+		notes := fmt.Sprintf("cooked in a batch of %d orders in %s (%s alone)", len(batch), cookingTime, alone)
+		errs[indexes[n]] = s.finishOrder(ctx, worker, orderMsg, &notes, rid)
+		untrack[n]()
+		if errs[indexes[n]] != nil {
+			continue
+		}
+
+		logger.Log(logger.DEBUG, "kitchen-worker", "order_completed", "order processing completed", rid,
+			map[string]interface{}{
+				"order_number":       orderMsg.OrderNumber,
+				"worker_name":        worker.Name,
+				"cooking_time":       cookingTime.String(),
+				"alone_cooking_time": alone.String(),
+			}, nil)
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "batch_completed", "batch cooking completed", rid,
+		map[string]interface{}{
+			"order_numbers": orderNumbers,
+			"worker_name":   worker.Name,
+			"cooking_time":  cookingTime.String(),
+		}, nil)
+
+	return errs
+}
+
+// SharesItems reports whether two orders have at least one item in common,
+// matching names the same way the prep-time table does.
+func SharesItems(a, b []rmq.OrderItem) bool {
+	names := make(map[string]struct{}, len(a))
+	for _, item := range a {
+		names[normalizeItemName(item.Name)] = struct{}{}
+	}
+	for _, item := range b {
+		if _, ok := names[normalizeItemName(item.Name)]; ok {
+			return true
+		}
+	}
+	return false
+}
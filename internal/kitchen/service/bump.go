@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"restaurant-system/internal/kitchen/model"
+)
+
+var (
+	NotPendingError  = errors.New("nothing waiting to be bumped")
+	BumpExpiredError = errors.New("bump hold expired")
+)
+
+// MaxBumpHold bounds how long a delivery stays unacked while it waits for a
+// bump. It stays below RabbitMQ's default consumer_timeout of 30 minutes, past
+// which the broker closes the channel and redelivers everything on it.
+const MaxBumpHold = 25 * time.Minute
+
+// Bumper holds cooked items until staff mark them ready. Each key is an
+// order number, or "order/station" for station tickets.
+type Bumper struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingBump
+}
+
+type pendingBump struct {
+	orderNumber string
+	startedAt   time.Time
+	done        chan struct{}
+}
+
+func NewBumper(timeout time.Duration) *Bumper {
+	if timeout <= 0 {
+		timeout = 15 * time.Minute
+	}
+	if timeout > MaxBumpHold {
+		timeout = MaxBumpHold
+	}
+	return &Bumper{timeout: timeout, pending: make(map[string]*pendingBump)}
+}
+
+// Wait blocks until the key is bumped or ctx is cancelled. onOverdue is
+// called once when the timeout elapses without a bump. After MaxBumpHold the
+// wait gives up with BumpExpiredError so that the caller can hand the delivery
+// back before the broker's consumer timeout does it for them.
+func (b *Bumper) Wait(ctx context.Context, key string, orderNumber string, onOverdue func(waited time.Duration)) error {
+	p := &pendingBump{orderNumber: orderNumber, startedAt: time.Now(), done: make(chan struct{})}
+
+	b.mu.Lock()
+	b.pending[key] = p
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, key)
+		b.mu.Unlock()
+	}()
+
+	overdue := time.NewTimer(b.timeout)
+	defer overdue.Stop()
+	expired := time.NewTimer(MaxBumpHold)
+	defer expired.Stop()
+
+	escalated := false
+	for {
+		select {
+		case <-p.done:
+			return nil
+		case <-overdue.C:
+			escalated = true
+			onOverdue(time.Since(p.startedAt))
+		case <-expired.C:
+			if !escalated {
+				onOverdue(time.Since(p.startedAt))
+			}
+			return BumpExpiredError
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *Bumper) Bump(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, ok := b.pending[key]
+	if !ok {
+		return NotPendingError
+	}
+
+	delete(b.pending, key)
+	close(p.done)
+	return nil
+}
+
+func (b *Bumper) Pending() []model.PendingBump {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	pending := make([]model.PendingBump, 0, len(b.pending))
+	for key, p := range b.pending {
+		pending = append(pending, model.PendingBump{
+			Key:            key,
+			OrderNumber:    p.orderNumber,
+			StartedAt:      p.startedAt,
+			ElapsedSeconds: int(now.Sub(p.startedAt).Seconds()),
+			Overdue:        now.Sub(p.startedAt) >= b.timeout,
+		})
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].StartedAt.Before(pending[j].StartedAt) })
+
+	return pending
+}
@@ -2,23 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 
 	"restaurant-system/internal/kitchen/infrastructure/rmq"
 	"restaurant-system/internal/kitchen/model"
 	"restaurant-system/pkg/logger"
+	"restaurant-system/pkg/orderstatus"
+	"restaurant-system/pkg/version"
 )
 
 type WorkerRepository interface {
-	CreateOrUpdateWorker(ctx context.Context, name string, workerType string, orderTypes []string) (*model.Worker, error)
-	UpdateWorkerHeartbeat(ctx context.Context, id int) error
+	CreateOrUpdateWorker(ctx context.Context, worker *model.Worker) (*model.Worker, error)
+	UpdateWorkerHeartbeat(ctx context.Context, id int, inFlight []string) error
+	UpdateWorkerStatus(ctx context.Context, id int, status string) error
 	MarkWorkerOffline(ctx context.Context, id int) error
 	IncrementOrdersProcessed(ctx context.Context, id int) error
+	HasShifts(ctx context.Context, workerName string) (bool, error)
+	GetCurrentShift(ctx context.Context, workerName string) (*model.Shift, error)
 }
 
 type OrderRepository interface {
-	UpdateOrderStatus(ctx context.Context, orderNumber string, status string, processedBy string) error
+	TransitionOrder(ctx context.Context, orderNumber string, from, to string, changedBy string, notes *string) error
 	CreateStatusLog(ctx context.Context, orderNumber string, status string, changedBy string, notes *string) error
 	GetOrderByNumber(ctx context.Context, orderNumber string) (*model.Order, error)
 }
@@ -27,27 +36,64 @@ type StatusPublisher interface {
 	PublishStatusUpdate(ctx context.Context, update *rmq.StatusUpdateMessage) error
 }
 
+type TicketRepository interface {
+	SplitOrder(ctx context.Context, orderNumber string, dispatcher string, tickets []*model.Ticket) (bool, error)
+	ClearPublishPending(ctx context.Context, ticketID int) error
+	ClaimTicket(ctx context.Context, ticketID int, workerName string) (bool, error)
+	ReleaseTicket(ctx context.Context, ticketID int) error
+	CompleteTicket(ctx context.Context, ticketID int, workerName string) (bool, error)
+}
+
+type TicketPublisher interface {
+	PublishTicket(ctx context.Context, ticket *rmq.TicketMessage) error
+}
+
 type KitchenService struct {
 	workerRepo WorkerRepository
 	orderRepo  OrderRepository
 	publisher  StatusPublisher
+	prepTime   *PrepTimeModel
+
+	ticketRepo      TicketRepository
+	ticketPublisher TicketPublisher
+	stationRouting  bool
+
+	// bumper is nil in auto bump mode.
+	bumper *Bumper
+
+	mu       sync.Mutex
+	inFlight map[string]time.Time
 }
 
-func NewKitchenService(wr WorkerRepository, or OrderRepository, sp StatusPublisher) *KitchenService {
+func NewKitchenService(wr WorkerRepository, or OrderRepository, sp StatusPublisher, pt *PrepTimeModel,
+	tr TicketRepository, tp TicketPublisher, stationRouting bool, bumper *Bumper) *KitchenService {
 	return &KitchenService{
-		workerRepo: wr,
-		orderRepo:  or,
-		publisher:  sp,
+		workerRepo:      wr,
+		orderRepo:       or,
+		publisher:       sp,
+		prepTime:        pt,
+		ticketRepo:      tr,
+		ticketPublisher: tp,
+		stationRouting:  stationRouting,
+		bumper:          bumper,
+		inFlight:        make(map[string]time.Time),
 	}
 }
 
-func (s *KitchenService) RegisterWorker(ctx context.Context, name string, orderTypes []string) (*model.Worker, error) {
+func (s *KitchenService) RegisterWorker(ctx context.Context, name string, orderTypes []string, stations []string, maxConcurrency int) (*model.Worker, error) {
 	workerType := "general"
-	if len(orderTypes) > 0 {
+	if len(orderTypes) > 0 || len(stations) > 0 {
 		workerType = "specialized"
 	}
 
-	worker, err := s.workerRepo.CreateOrUpdateWorker(ctx, name, workerType, orderTypes)
+	worker, err := s.workerRepo.CreateOrUpdateWorker(ctx, &model.Worker{
+		Name:           name,
+		Type:           workerType,
+		OrderTypes:     orderTypes,
+		Stations:       stations,
+		MaxConcurrency: maxConcurrency,
+		Version:        version.Version,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to register worker: %w", err)
 	}
@@ -55,8 +101,80 @@ func (s *KitchenService) RegisterWorker(ctx context.Context, name string, orderT
 	return worker, nil
 }
 
+// CheckShift warns when a worker that has shifts starts outside all of them,
+// or when its current shift plans stations the worker was not started with.
+// Workers without any shift are not on the schedule and are not checked.
+func (s *KitchenService) CheckShift(ctx context.Context, worker *model.Worker, rid string) {
+	scheduled, err := s.workerRepo.HasShifts(ctx, worker.Name)
+	if err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "shift_lookup_failed", "failed to look up worker shifts", rid,
+			map[string]interface{}{"worker_name": worker.Name}, err)
+		return
+	}
+	if !scheduled {
+		return
+	}
+
+	shift, err := s.workerRepo.GetCurrentShift(ctx, worker.Name)
+	if err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "shift_lookup_failed", "failed to look up worker shift", rid,
+			map[string]interface{}{"worker_name": worker.Name}, err)
+		return
+	}
+	if shift == nil {
+		logger.Log(logger.WARN, "kitchen-worker", "worker_outside_shift", "worker started outside its scheduled shift", rid,
+			map[string]interface{}{"worker_name": worker.Name}, nil)
+		return
+	}
+
+	var missing []string
+	for _, station := range shift.Stations {
+		if !slices.Contains(worker.Stations, station) {
+			missing = append(missing, station)
+		}
+	}
+	if len(missing) > 0 {
+		logger.Log(logger.WARN, "kitchen-worker", "shift_stations_mismatch", "worker does not cover the stations of its shift", rid,
+			map[string]interface{}{
+				"worker_name":      worker.Name,
+				"shift_stations":   shift.Stations,
+				"worker_stations":  worker.Stations,
+				"missing_stations": missing,
+			}, nil)
+	}
+}
+
 func (s *KitchenService) SendHeartbeat(ctx context.Context, workerID int) error {
-	return s.workerRepo.UpdateWorkerHeartbeat(ctx, workerID)
+	return s.workerRepo.UpdateWorkerHeartbeat(ctx, workerID, s.InFlight())
+}
+
+// InFlight returns the numbers of the orders currently being cooked.
+func (s *KitchenService) InFlight() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]string, 0, len(s.inFlight))
+	for number := range s.inFlight {
+		orders = append(orders, number)
+	}
+	sort.Strings(orders)
+	return orders
+}
+
+func (s *KitchenService) trackOrder(orderNumber string) func() {
+	s.mu.Lock()
+	s.inFlight[orderNumber] = time.Now()
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		delete(s.inFlight, orderNumber)
+		s.mu.Unlock()
+	}
+}
+
+func (s *KitchenService) UpdateWorkerStatus(ctx context.Context, workerID int, status string) error {
+	return s.workerRepo.UpdateWorkerStatus(ctx, workerID, status)
 }
 
 func (s *KitchenService) MarkWorkerOffline(ctx context.Context, workerID int) error {
@@ -77,50 +195,126 @@ func (s *KitchenService) ProcessOrder(ctx context.Context, worker *model.Worker,
 			"worker_name":  worker.Name,
 		}, nil)
 
-	if len(worker.OrderTypes) > 0 {
-		canHandle := false
-		for _, t := range worker.OrderTypes {
-			if t == orderMsg.OrderType {
-				canHandle = true
-				break
-			}
+	if err := s.checkOrderType(worker, orderMsg, rid); err != nil {
+		return err
+	}
+
+	if s.stationRouting {
+		return s.dispatchOrder(ctx, worker, orderMsg, rid)
+	}
+
+	claimed, err := s.claimOrder(ctx, worker, orderMsg, rid)
+	if err != nil || !claimed {
+		return err
+	}
+
+	defer s.trackOrder(orderMsg.OrderNumber)()
+
+	cookingTime := s.prepTime.OrderDuration(orderMsg.Items)
+	startedAt := time.Now()
+	s.publishCooking(ctx, worker, orderMsg, startedAt, startedAt.Add(cookingTime), rid)
+
+	if err := s.cook(ctx, orderMsg.OrderNumber, orderMsg.OrderNumber, time.Until(startedAt.Add(cookingTime)), worker, rid); err != nil {
+		s.releaseOrder(orderMsg.OrderNumber, worker.Name, rid)
+		return fmt.Errorf("cooking interrupted: %w", err)
+	}
+
+	if err := s.finishOrder(ctx, worker, orderMsg, nil, rid); err != nil {
+		return err
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "order_completed", "order processing completed", rid,
+		map[string]interface{}{
+			"order_number": orderMsg.OrderNumber,
+			"worker_name":  worker.Name,
+			"cooking_time": cookingTime.String(),
+		}, nil)
+
+	return nil
+}
+
+func (s *KitchenService) checkOrderType(worker *model.Worker, orderMsg *rmq.OrderMessage, rid string) error {
+	if len(worker.OrderTypes) == 0 {
+		return nil
+	}
+
+	for _, t := range worker.OrderTypes {
+		if t == orderMsg.OrderType {
+			return nil
 		}
-		if !canHandle {
-			logger.Log(logger.DEBUG, "kitchen-worker", "order_rejected", "worker cannot handle this order type", rid,
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "order_rejected", "worker cannot handle this order type", rid,
+		map[string]interface{}{
+			"order_number": orderMsg.OrderNumber,
+			"order_type":   orderMsg.OrderType,
+			"worker_types": worker.OrderTypes,
+		}, nil)
+	return fmt.Errorf("worker cannot handle order type %s", orderMsg.OrderType)
+}
+
+// Superseded reports whether a copy of a received order was republished at a
+// higher priority since this message was sent, as aging and escalation do.
+// The older copy can be acked unprocessed: the newer one is queued, or still
+// flagged for the reaper to publish. When the order cannot be read the
+// message is kept and the claim decides.
+func (s *KitchenService) Superseded(ctx context.Context, orderMsg *rmq.OrderMessage) bool {
+	rid := ""
+	if v := ctx.Value("request_id"); v != nil {
+		if str, ok := v.(string); ok {
+			rid = str
+		}
+	}
+
+	order, err := s.orderRepo.GetOrderByNumber(ctx, orderMsg.OrderNumber)
+	if err != nil {
+		return false
+	}
+	if order.Status != orderstatus.Received || orderMsg.Priority >= order.Priority {
+		return false
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "order_superseded", "dropped copy republished at a higher priority", rid,
+		map[string]interface{}{
+			"order_number":     orderMsg.OrderNumber,
+			"message_priority": orderMsg.Priority,
+			"order_priority":   order.Priority,
+		}, nil)
+	return true
+}
+
+// claimOrder moves the order from received to cooking. The conditional
+// transition is the claim: a redelivered or republished copy of an order that
+// is already past received reports false and is acked, not redone.
+func (s *KitchenService) claimOrder(ctx context.Context, worker *model.Worker, orderMsg *rmq.OrderMessage, rid string) (bool, error) {
+	if err := s.orderRepo.TransitionOrder(ctx, orderMsg.OrderNumber, orderstatus.Received, orderstatus.Cooking, worker.Name, nil); err != nil {
+		if errors.Is(err, orderstatus.StaleStatusError) {
+			logger.Log(logger.DEBUG, "kitchen-worker", "order_skipped", "order already picked up", rid,
 				map[string]interface{}{
 					"order_number": orderMsg.OrderNumber,
-					"order_type":   orderMsg.OrderType,
-					"worker_types": worker.OrderTypes,
+					"reason":       err.Error(),
 				}, nil)
-			return fmt.Errorf("worker cannot handle order type %s", orderMsg.OrderType)
+			return false, nil
 		}
-	}
-
-	if err := s.orderRepo.UpdateOrderStatus(ctx, orderMsg.OrderNumber, "cooking", worker.Name); err != nil {
 		logger.Log(logger.ERROR, "kitchen-worker", "status_update_failed", "failed to update order status to cooking", rid,
 			map[string]interface{}{
 				"order_number": orderMsg.OrderNumber,
 				"error":        err.Error(),
 			}, err)
-		return fmt.Errorf("failed to update order status: %w", err)
+		return false, fmt.Errorf("failed to update order status: %w", err)
 	}
 
-	if err := s.orderRepo.CreateStatusLog(ctx, orderMsg.OrderNumber, "cooking", worker.Name, nil); err != nil {
-		logger.Log(logger.ERROR, "kitchen-worker", "status_log_failed", "failed to create status log", rid,
-			map[string]interface{}{
-				"order_number": orderMsg.OrderNumber,
-				"error":        err.Error(),
-			}, err)
-		return fmt.Errorf("failed to create status log: %w", err)
-	}
+	return true, nil
+}
 
+func (s *KitchenService) publishCooking(ctx context.Context, worker *model.Worker, orderMsg *rmq.OrderMessage, startedAt, eta time.Time, rid string) {
 	update := &rmq.StatusUpdateMessage{
 		OrderNumber:         orderMsg.OrderNumber,
-		OldStatus:           "received",
-		NewStatus:           "cooking",
+		OldStatus:           orderstatus.Received,
+		NewStatus:           orderstatus.Cooking,
 		ChangedBy:           worker.Name,
-		Timestamp:           time.Now(),
-		EstimatedCompletion: time.Now().Add(10 * time.Minute),
+		Timestamp:           startedAt,
+		EstimatedCompletion: eta,
 	}
 	if err := s.publisher.PublishStatusUpdate(ctx, update); err != nil {
 		logger.Log(logger.ERROR, "kitchen-worker", "status_publish_failed", "failed to publish status update", rid,
@@ -129,22 +323,20 @@ func (s *KitchenService) ProcessOrder(ctx context.Context, worker *model.Worker,
 				"error":        err.Error(),
 			}, err)
 	}
+}
 
-	var cookingTime time.Duration
-	switch orderMsg.OrderType {
-	case "dine_in":
-		cookingTime = 8 * time.Second
-	case "takeout":
-		cookingTime = 10 * time.Second
-	case "delivery":
-		cookingTime = 12 * time.Second
-	default:
-		cookingTime = 10 * time.Second
-	}
-
-	time.Sleep(cookingTime)
-
-	if err := s.orderRepo.UpdateOrderStatus(ctx, orderMsg.OrderNumber, "ready", worker.Name); err != nil {
+// finishOrder moves a cooked order to ready, counts it for the worker and
+// notifies subscribers. An order changed elsewhere while cooking is dropped.
+func (s *KitchenService) finishOrder(ctx context.Context, worker *model.Worker, orderMsg *rmq.OrderMessage, notes *string, rid string) error {
+	if err := s.orderRepo.TransitionOrder(ctx, orderMsg.OrderNumber, orderstatus.Cooking, orderstatus.Ready, worker.Name, notes); err != nil {
+		if errors.Is(err, orderstatus.StaleStatusError) {
+			logger.Log(logger.DEBUG, "kitchen-worker", "order_ready_discarded", "order changed while cooking", rid,
+				map[string]interface{}{
+					"order_number": orderMsg.OrderNumber,
+					"reason":       err.Error(),
+				}, nil)
+			return nil
+		}
 		logger.Log(logger.ERROR, "kitchen-worker", "status_update_failed", "failed to update order status to ready", rid,
 			map[string]interface{}{
 				"order_number": orderMsg.OrderNumber,
@@ -153,15 +345,6 @@ func (s *KitchenService) ProcessOrder(ctx context.Context, worker *model.Worker,
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
 
-	if err := s.orderRepo.CreateStatusLog(ctx, orderMsg.OrderNumber, "ready", worker.Name, nil); err != nil {
-		logger.Log(logger.ERROR, "kitchen-worker", "status_log_failed", "failed to create status log", rid,
-			map[string]interface{}{
-				"order_number": orderMsg.OrderNumber,
-				"error":        err.Error(),
-			}, err)
-		return fmt.Errorf("failed to create status log: %w", err)
-	}
-
 	if err := s.workerRepo.IncrementOrdersProcessed(ctx, worker.ID); err != nil {
 		logger.Log(logger.ERROR, "kitchen-worker", "worker_update_failed", "failed to increment orders processed", rid,
 			map[string]interface{}{
@@ -171,13 +354,14 @@ func (s *KitchenService) ProcessOrder(ctx context.Context, worker *model.Worker,
 			}, err)
 	}
 
-	update = &rmq.StatusUpdateMessage{
+	now := time.Now()
+	update := &rmq.StatusUpdateMessage{
 		OrderNumber:         orderMsg.OrderNumber,
-		OldStatus:           "cooking",
-		NewStatus:           "ready",
+		OldStatus:           orderstatus.Cooking,
+		NewStatus:           orderstatus.Ready,
 		ChangedBy:           worker.Name,
-		Timestamp:           time.Now(),
-		EstimatedCompletion: time.Now(),
+		Timestamp:           now,
+		EstimatedCompletion: now,
 	}
 	if err := s.publisher.PublishStatusUpdate(ctx, update); err != nil {
 		logger.Log(logger.ERROR, "kitchen-worker", "status_publish_failed", "failed to publish status update", rid,
@@ -187,12 +371,64 @@ func (s *KitchenService) ProcessOrder(ctx context.Context, worker *model.Worker,
 			}, err)
 	}
 
-	logger.Log(logger.DEBUG, "kitchen-worker", "order_completed", "order processing completed", rid,
-		map[string]interface{}{
-			"order_number": orderMsg.OrderNumber,
-			"worker_name":  worker.Name,
-			"cooking_time": cookingTime.String(),
-		}, nil)
-
 	return nil
 }
+
+// releaseOrder puts an interrupted order back to received so that the
+// redelivered message can be picked up again. It runs on a fresh context
+// because the order's own context is already cancelled.
+func (s *KitchenService) releaseOrder(orderNumber string, workerName string, rid string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	notes := "cooking interrupted, returned to queue"
+	if err := s.orderRepo.TransitionOrder(ctx, orderNumber, orderstatus.Cooking, orderstatus.Received, workerName, &notes); err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "status_update_failed", "failed to return order to received", rid,
+			map[string]interface{}{"order_number": orderNumber}, err)
+	}
+}
+
+// cook simulates cooking for the given duration, or in manual bump mode
+// waits until staff bump the key through the worker's HTTP API.
+func (s *KitchenService) cook(ctx context.Context, key string, orderNumber string, duration time.Duration, worker *model.Worker, rid string) error {
+	if s.bumper == nil {
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return s.bumper.Wait(ctx, key, orderNumber, func(waited time.Duration) {
+		logger.Log(logger.ERROR, "kitchen-worker", "bump_overdue", "order has not been bumped", rid,
+			map[string]interface{}{
+				"order_number": orderNumber,
+				"key":          key,
+				"waited":       waited.Round(time.Second).String(),
+			}, fmt.Errorf("bump overdue"))
+
+		notes := fmt.Sprintf("escalated: %s not bumped after %s", key, waited.Round(time.Second))
+		if err := s.orderRepo.CreateStatusLog(ctx, orderNumber, orderstatus.Cooking, worker.Name, &notes); err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "status_log_failed", "failed to log bump escalation", rid,
+				map[string]interface{}{"order_number": orderNumber}, err)
+		}
+	})
+}
+
+func (s *KitchenService) Bump(key string) error {
+	if s.bumper == nil {
+		return NotPendingError
+	}
+	return s.bumper.Bump(key)
+}
+
+func (s *KitchenService) PendingBumps() []model.PendingBump {
+	if s.bumper == nil {
+		return []model.PendingBump{}
+	}
+	return s.bumper.Pending()
+}
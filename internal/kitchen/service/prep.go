@@ -0,0 +1,102 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"restaurant-system/config"
+	"restaurant-system/internal/kitchen/infrastructure/rmq"
+)
+
+type PrepTimeModel struct {
+	defaultItem config.ItemPrepConfig
+	items       map[string]config.ItemPrepConfig
+}
+
+func NewPrepTimeModel(cfg config.KitchenConfig) *PrepTimeModel {
+	items := make(map[string]config.ItemPrepConfig, len(cfg.Items))
+	for name, prep := range cfg.Items {
+		items[normalizeItemName(name)] = prep
+	}
+	return &PrepTimeModel{defaultItem: cfg.DefaultItem, items: items}
+}
+
+// ItemDuration is base + per_unit * ceil(quantity / parallelism).
+func (m *PrepTimeModel) ItemDuration(name string, quantity int) time.Duration {
+	prep, ok := m.items[normalizeItemName(name)]
+	if !ok {
+		prep = m.defaultItem
+	}
+
+	parallelism := max(prep.Parallelism, 1)
+	rounds := (max(quantity, 1) + parallelism - 1) / parallelism
+	seconds := prep.BaseSeconds + prep.PerUnitSeconds*float64(rounds)
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// OrderDuration assumes a single cook prepares the items one after another.
+func (m *PrepTimeModel) OrderDuration(items []rmq.OrderItem) time.Duration {
+	var total time.Duration
+	for _, item := range items {
+		total += m.ItemDuration(item.Name, item.Quantity)
+	}
+	return total
+}
+
+// MergeItems combines identical items from several orders so that each is
+// prepared once in a larger batch.
+func (m *PrepTimeModel) MergeItems(orders ...[]rmq.OrderItem) []rmq.OrderItem {
+	var merged []rmq.OrderItem
+	index := make(map[string]int)
+	for _, items := range orders {
+		for _, item := range items {
+			key := normalizeItemName(item.Name)
+			if i, ok := index[key]; ok {
+				merged[i].Quantity += item.Quantity
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, rmq.OrderItem{Name: item.Name, Quantity: item.Quantity})
+		}
+	}
+	return merged
+}
+
+func (m *PrepTimeModel) Station(name string) string {
+	if prep, ok := m.items[normalizeItemName(name)]; ok && prep.Station != "" {
+		return prep.Station
+	}
+	return m.defaultItem.Station
+}
+
+// Stations lists every station an item can be routed to.
+func (m *PrepTimeModel) Stations() []string {
+	seen := map[string]bool{m.defaultItem.Station: true}
+	for _, prep := range m.items {
+		seen[prep.Station] = true
+	}
+	delete(seen, "")
+
+	stations := make([]string, 0, len(seen))
+	for station := range seen {
+		stations = append(stations, station)
+	}
+	sort.Strings(stations)
+	return stations
+}
+
+// SplitByStation groups the order's items into one ticket per station.
+func (m *PrepTimeModel) SplitByStation(items []rmq.OrderItem) map[string][]rmq.OrderItem {
+	stations := make(map[string][]rmq.OrderItem)
+	for _, item := range items {
+		station := m.Station(item.Name)
+		stations[station] = append(stations[station], item)
+	}
+	return stations
+}
+
+func normalizeItemName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
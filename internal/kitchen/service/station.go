@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"restaurant-system/internal/kitchen/infrastructure/rmq"
+	"restaurant-system/internal/kitchen/model"
+	"restaurant-system/pkg/logger"
+	"restaurant-system/pkg/orderstatus"
+)
+
+// dispatchOrder splits an order into station tickets instead of cooking it.
+// Stations cook in parallel, so the estimate is the slowest ticket. A ticket
+// that cannot be published stays flagged in the database and the order
+// service's reaper publishes it, so a duplicate of the order message, such
+// as an aging or escalation republish, never publishes tickets again.
+func (s *KitchenService) dispatchOrder(ctx context.Context, worker *model.Worker, orderMsg *rmq.OrderMessage, rid string) error {
+	byStation := s.prepTime.SplitByStation(orderMsg.Items)
+
+	stations := make([]string, 0, len(byStation))
+	for station := range byStation {
+		stations = append(stations, station)
+	}
+	sort.Strings(stations)
+
+	tickets := make([]*model.Ticket, 0, len(stations))
+	var slowest time.Duration
+	for _, station := range stations {
+		ticket := &model.Ticket{OrderNumber: orderMsg.OrderNumber, Station: station}
+		for _, item := range byStation[station] {
+			ticket.Items = append(ticket.Items, model.TicketItem{Name: item.Name, Quantity: item.Quantity})
+		}
+		tickets = append(tickets, ticket)
+
+		slowest = max(slowest, s.prepTime.OrderDuration(byStation[station]))
+	}
+
+	claimed, err := s.ticketRepo.SplitOrder(ctx, orderMsg.OrderNumber, worker.Name, tickets)
+	if err != nil {
+		return fmt.Errorf("failed to split order: %w", err)
+	}
+	if !claimed {
+		logger.Log(logger.DEBUG, "kitchen-worker", "order_skipped", "order already picked up", rid,
+			map[string]interface{}{"order_number": orderMsg.OrderNumber}, nil)
+		return nil
+	}
+
+	s.publishTickets(ctx, orderMsg, tickets, rid)
+
+	now := time.Now()
+	update := &rmq.StatusUpdateMessage{
+		OrderNumber:         orderMsg.OrderNumber,
+		OldStatus:           orderstatus.Received,
+		NewStatus:           orderstatus.Cooking,
+		ChangedBy:           worker.Name,
+		Timestamp:           now,
+		EstimatedCompletion: now.Add(slowest),
+	}
+	if err := s.publisher.PublishStatusUpdate(ctx, update); err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "status_publish_failed", "failed to publish status update", rid,
+			map[string]interface{}{"order_number": orderMsg.OrderNumber}, err)
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "order_dispatched", "order split into station tickets", rid,
+		map[string]interface{}{
+			"order_number": orderMsg.OrderNumber,
+			"stations":     stations,
+		}, nil)
+
+	return nil
+}
+
+// publishTickets publishes every ticket and clears its publish flag. Failures
+// are only logged: the flag stays set and the reaper publishes the ticket.
+func (s *KitchenService) publishTickets(ctx context.Context, orderMsg *rmq.OrderMessage, tickets []*model.Ticket, rid string) {
+	for _, ticket := range tickets {
+		items := make([]rmq.OrderItem, 0, len(ticket.Items))
+		for _, item := range ticket.Items {
+			items = append(items, rmq.OrderItem{Name: item.Name, Quantity: item.Quantity})
+		}
+
+		msg := &rmq.TicketMessage{
+			TicketID:    ticket.ID,
+			OrderNumber: orderMsg.OrderNumber,
+			OrderType:   orderMsg.OrderType,
+			Station:     ticket.Station,
+			Items:       items,
+			Priority:    orderMsg.Priority,
+		}
+		if err := s.ticketPublisher.PublishTicket(ctx, msg); err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "ticket_publish_failed", "failed to publish station ticket, left for the reaper", rid,
+				map[string]interface{}{
+					"order_number": orderMsg.OrderNumber,
+					"ticket_id":    ticket.ID,
+					"station":      ticket.Station,
+				}, err)
+			continue
+		}
+
+		if err := s.ticketRepo.ClearPublishPending(ctx, ticket.ID); err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "db_update_failed", "failed to clear ticket publish flag", rid,
+				map[string]interface{}{"order_number": orderMsg.OrderNumber, "ticket_id": ticket.ID}, err)
+		}
+	}
+}
+
+func (s *KitchenService) ProcessTicket(ctx context.Context, worker *model.Worker, ticketMsg *rmq.TicketMessage) error {
+	rid := ""
+	if v := ctx.Value("request_id"); v != nil {
+		if str, ok := v.(string); ok {
+			rid = str
+		}
+	}
+
+	claimed, err := s.ticketRepo.ClaimTicket(ctx, ticketMsg.TicketID, worker.Name)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		logger.Log(logger.DEBUG, "kitchen-worker", "ticket_skipped", "ticket already picked up", rid,
+			map[string]interface{}{"order_number": ticketMsg.OrderNumber, "ticket_id": ticketMsg.TicketID}, nil)
+		return nil
+	}
+
+	key := fmt.Sprintf("%s/%s", ticketMsg.OrderNumber, ticketMsg.Station)
+	defer s.trackOrder(key)()
+
+	cookingTime := s.prepTime.OrderDuration(ticketMsg.Items)
+	if err := s.cook(ctx, key, ticketMsg.OrderNumber, cookingTime, worker, rid); err != nil {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.ticketRepo.ReleaseTicket(releaseCtx, ticketMsg.TicketID); err != nil {
+			logger.Log(logger.ERROR, "kitchen-worker", "ticket_release_failed", "failed to release ticket", rid,
+				map[string]interface{}{"ticket_id": ticketMsg.TicketID}, err)
+		}
+		return fmt.Errorf("cooking interrupted: %w", err)
+	}
+
+	ready, err := s.ticketRepo.CompleteTicket(ctx, ticketMsg.TicketID, worker.Name)
+	if err != nil {
+		return err
+	}
+
+	logger.Log(logger.DEBUG, "kitchen-worker", "ticket_completed", "station ticket completed", rid,
+		map[string]interface{}{
+			"order_number": ticketMsg.OrderNumber,
+			"station":      ticketMsg.Station,
+			"cooking_time": cookingTime.String(),
+			"order_ready":  ready,
+		}, nil)
+
+	if !ready {
+		return nil
+	}
+
+	if err := s.workerRepo.IncrementOrdersProcessed(ctx, worker.ID); err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "worker_update_failed", "failed to increment orders processed", rid,
+			map[string]interface{}{"worker_id": worker.ID, "order_number": ticketMsg.OrderNumber}, err)
+	}
+
+	now := time.Now()
+	update := &rmq.StatusUpdateMessage{
+		OrderNumber:         ticketMsg.OrderNumber,
+		OldStatus:           orderstatus.Cooking,
+		NewStatus:           orderstatus.Ready,
+		ChangedBy:           worker.Name,
+		Timestamp:           now,
+		EstimatedCompletion: now,
+	}
+	if err := s.publisher.PublishStatusUpdate(ctx, update); err != nil {
+		logger.Log(logger.ERROR, "kitchen-worker", "status_publish_failed", "failed to publish status update", rid,
+			map[string]interface{}{"order_number": ticketMsg.OrderNumber}, err)
+	}
+
+	return nil
+}
@@ -4,4 +4,6 @@ type CreateOrderResponse struct {
 	OrderNumber string  `json:"order_number"`
 	Status      string  `json:"status"`
 	TotalAmount float64 `json:"total_amount"`
+
+	EstimatedCompletion *string `json:"estimated_completion,omitempty"`
 }
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -37,6 +38,9 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 		Type:            req.OrderType,
 		TableNumber:     req.TableNumber,
 		DeliveryAddress: req.DeliveryAddress,
+		PickupSlotID:    req.PickupSlotID,
+		LoyaltyID:       req.LoyaltyID,
+		RedeemPoints:    req.RedeemPoints,
 	}
 
 	for _, item := range req.Items {
@@ -52,9 +56,17 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 		logger.Log(logger.ERROR, "order-service", "order_creation_failed", "failed to create order", rid,
 			map[string]interface{}{"customer_name": req.CustomerName}, err)
 
-		if err == model.ValidationError {
-			http.Error(w, "Validation error", http.StatusBadRequest)
-		} else {
+		switch {
+		case errors.Is(err, model.ValidationError), errors.Is(err, model.InsufficientPointsError):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, model.SlotUnavailableError):
+			http.Error(w, "Pickup slot is unavailable", http.StatusConflict)
+		case errors.Is(err, model.OutOfStockError):
+			http.Error(w, "Not enough stock for this order", http.StatusConflict)
+		case errors.Is(err, model.KitchenOverloadedError):
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "Kitchen is at capacity, please try again later", http.StatusServiceUnavailable)
+		default:
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
 		return
@@ -68,6 +80,12 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 		"status":       result.Status,
 		"total_amount": result.TotalAmount,
 	}
+	if result.DiscountAmount > 0 {
+		response["discount_amount"] = result.DiscountAmount
+	}
+	if result.EstimatedCompletion != nil {
+		response["estimated_completion"] = result.EstimatedCompletion.Format(time.RFC3339)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -137,3 +155,255 @@ func (h *OrderHandler) GetOrderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *OrderHandler) GetSlotsHandler(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	ctx := context.WithValue(r.Context(), "request_id", rid)
+
+	date := time.Now().UTC()
+	if d := r.URL.Query().Get("date"); d != "" {
+		parsed, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			http.Error(w, "Invalid date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	slots, err := h.service.GetSlots(ctx, date)
+	if err != nil {
+		logger.Log(logger.ERROR, "order-service", "get_slots_failed", "failed to get pickup slots", rid,
+			map[string]interface{}{"date": date.Format("2006-01-02")}, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	response := make([]map[string]interface{}, 0, len(slots))
+	for _, slot := range slots {
+		response = append(response, map[string]interface{}{
+			"id":               slot.ID,
+			"slot_start":       slot.Start.Format(time.RFC3339),
+			"slot_end":         slot.End.Format(time.RFC3339),
+			"available":        slot.Available(now),
+			"remaining_orders": slot.MaxOrders - slot.BookedOrders,
+			"remaining_items":  slot.MaxItems - slot.BookedItems,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+func (h *OrderHandler) EscalateOrderHandler(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	ctx := context.WithValue(r.Context(), "request_id", rid)
+
+	orderNumber := r.PathValue("orderNumber")
+
+	var req model.EscalateOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log(logger.ERROR, "order-service", "request_parse_failed", "failed to parse request body", rid, nil, err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.EscalateOrder(ctx, orderNumber, &req)
+	if err != nil {
+		logger.Log(logger.ERROR, "order-service", "order_escalation_failed", "failed to escalate order", rid,
+			map[string]interface{}{"order_number": orderNumber}, err)
+
+		switch {
+		case errors.Is(err, model.ValidationError):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, model.OrderNotFoundError):
+			http.Error(w, "Order not found", http.StatusNotFound)
+		case errors.Is(err, model.OrderStateError):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"order_number": order.Number,
+		"status":       order.Status,
+		"priority":     order.Priority,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+func (h *OrderHandler) ChangeStatusHandler(w http.ResponseWriter, r *http.Request, to model.OrderStatus) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	ctx := context.WithValue(r.Context(), "request_id", rid)
+
+	orderNumber := r.PathValue("orderNumber")
+
+	var req model.ChangeStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log(logger.ERROR, "order-service", "request_parse_failed", "failed to parse request body", rid, nil, err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.ChangeStatus(ctx, orderNumber, to, &req)
+	if err != nil {
+		logger.Log(logger.ERROR, "order-service", "order_status_change_failed", "failed to change order status", rid,
+			map[string]interface{}{"order_number": orderNumber, "new_status": to}, err)
+
+		switch {
+		case errors.Is(err, model.ValidationError):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, model.OrderNotFoundError):
+			http.Error(w, "Order not found", http.StatusNotFound)
+		case errors.Is(err, model.OrderStateError):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"order_number": order.Number,
+		"status":       order.Status,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+func (h *OrderHandler) GetLoyaltyAccountHandler(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	ctx := context.WithValue(r.Context(), "request_id", rid)
+
+	loyaltyID := r.PathValue("loyaltyID")
+
+	account, err := h.service.GetLoyaltyAccount(ctx, loyaltyID)
+	if err != nil {
+		logger.Log(logger.ERROR, "order-service", "get_loyalty_failed", "failed to get loyalty account", rid,
+			map[string]interface{}{"loyalty_id": loyaltyID}, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(account)
+	if err != nil {
+		return
+	}
+}
+
+func (h *OrderHandler) SubmitFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	ctx := context.WithValue(r.Context(), "request_id", rid)
+
+	orderNumber := r.PathValue("orderNumber")
+
+	var req model.FeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log(logger.ERROR, "order-service", "request_parse_failed", "failed to parse request body", rid, nil, err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	feedback, err := h.service.SubmitFeedback(ctx, orderNumber, &req)
+	if err != nil {
+		logger.Log(logger.ERROR, "order-service", "feedback_failed", "failed to submit feedback", rid,
+			map[string]interface{}{"order_number": orderNumber}, err)
+
+		switch {
+		case errors.Is(err, model.ValidationError):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, model.OrderNotFoundError):
+			http.Error(w, "Order not found", http.StatusNotFound)
+		case errors.Is(err, model.OrderStateError), errors.Is(err, model.FeedbackExistsError):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	logger.Log(logger.DEBUG, "order-service", "feedback_received", "order feedback received", rid,
+		map[string]interface{}{"order_number": orderNumber, "rating": feedback.Rating}, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(feedback)
+	if err != nil {
+		return
+	}
+}
+
+func (h *OrderHandler) GetInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	ctx := context.WithValue(r.Context(), "request_id", rid)
+
+	inventory, err := h.service.GetInventory(ctx)
+	if err != nil {
+		logger.Log(logger.ERROR, "order-service", "get_inventory_failed", "failed to get inventory", rid, nil, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(inventory)
+	if err != nil {
+		return
+	}
+}
+
+func (h *OrderHandler) RestockHandler(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	ctx := context.WithValue(r.Context(), "request_id", rid)
+
+	ingredient := r.PathValue("ingredient")
+
+	var req model.RestockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log(logger.ERROR, "order-service", "request_parse_failed", "failed to parse request body", rid, nil, err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ing, err := h.service.Restock(ctx, ingredient, &req)
+	if err != nil {
+		logger.Log(logger.ERROR, "order-service", "restock_failed", "failed to restock ingredient", rid,
+			map[string]interface{}{"ingredient": ingredient}, err)
+
+		switch {
+		case errors.Is(err, model.ValidationError):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, model.IngredientNotFoundError):
+			http.Error(w, "Ingredient not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(ing)
+	if err != nil {
+		return
+	}
+}
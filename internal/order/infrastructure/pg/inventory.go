@@ -0,0 +1,166 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"restaurant-system/internal/order/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetUnavailableItems returns the menu items among names that cannot be made
+// even once because an ingredient has run out.
+func (r *OrderRepository) GetUnavailableItems(ctx context.Context, names []string) ([]string, error) {
+	query := `
+		SELECT DISTINCT rc.item_name
+		FROM recipes rc
+		JOIN ingredients i ON i.id = rc.ingredient_id
+		WHERE ($1::text[] IS NULL OR rc.item_name = ANY($1))
+		  AND i.stock - i.reserved < rc.quantity
+		ORDER BY rc.item_name
+	`
+	rows, err := r.db.Query(ctx, query, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unavailable items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, name)
+	}
+	return items, rows.Err()
+}
+
+// ReserveStock sets aside the ingredients for the order's items. It returns
+// false, reserving nothing, if any ingredient is short. Ingredients are
+// updated in id order so concurrent orders cannot deadlock.
+func (r *OrderRepository) ReserveStock(ctx context.Context, tx pgx.Tx, orderID int, items []model.OrderItem) (bool, error) {
+	names := make([]string, len(items))
+	quantities := make([]int, len(items))
+	for i, item := range items {
+		names[i] = model.RecipeName(item.Name)
+		quantities[i] = item.Quantity
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT rc.ingredient_id, SUM(rc.quantity * x.quantity)::float8
+		FROM recipes rc
+		JOIN unnest($1::text[], $2::int[]) AS x(name, quantity) ON rc.item_name = x.name
+		GROUP BY rc.ingredient_id
+		ORDER BY rc.ingredient_id
+	`, names, quantities)
+	if err != nil {
+		return false, fmt.Errorf("failed to compute stock requirements: %w", err)
+	}
+
+	type requirement struct {
+		ingredientID int
+		quantity     float64
+	}
+	var required []requirement
+	for rows.Next() {
+		var req requirement
+		if err := rows.Scan(&req.ingredientID, &req.quantity); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to scan stock requirement: %w", err)
+		}
+		required = append(required, req)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	for _, req := range required {
+		tag, err := tx.Exec(ctx, `
+			UPDATE ingredients SET reserved = reserved + $2, updated_at = NOW()
+			WHERE id = $1 AND stock - reserved >= $2
+		`, req.ingredientID, req.quantity)
+		if err != nil {
+			return false, fmt.Errorf("failed to reserve stock: %w", err)
+		}
+		if tag.RowsAffected() != 1 {
+			return false, nil
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO stock_reservations (order_id, ingredient_id, quantity)
+			VALUES ($1, $2, $3)
+		`, orderID, req.ingredientID, req.quantity)
+		if err != nil {
+			return false, fmt.Errorf("failed to create stock reservation: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// ReleaseStock returns the order's outstanding reservations to the pool.
+// Stock the kitchen has already consumed stays consumed.
+func (r *OrderRepository) ReleaseStock(ctx context.Context, tx pgx.Tx, orderID int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE ingredients i SET reserved = i.reserved - sr.quantity, updated_at = NOW()
+		FROM stock_reservations sr
+		WHERE sr.order_id = $1 AND sr.status = 'reserved' AND i.id = sr.ingredient_id
+	`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to release stock: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE stock_reservations SET status = 'released'
+		WHERE order_id = $1 AND status = 'reserved'
+	`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to release stock reservations: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OrderRepository) GetIngredients(ctx context.Context) ([]model.Ingredient, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, unit, stock::float8, reserved::float8, updated_at
+		FROM ingredients
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ingredients: %w", err)
+	}
+	defer rows.Close()
+
+	ingredients := []model.Ingredient{}
+	for rows.Next() {
+		var ing model.Ingredient
+		if err := rows.Scan(&ing.ID, &ing.Name, &ing.Unit, &ing.Stock, &ing.Reserved, &ing.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ingredient: %w", err)
+		}
+		ing.Available = ing.Stock - ing.Reserved
+		ingredients = append(ingredients, ing)
+	}
+	return ingredients, rows.Err()
+}
+
+func (r *OrderRepository) Restock(ctx context.Context, name string, quantity float64) (*model.Ingredient, error) {
+	var ing model.Ingredient
+	err := r.db.QueryRow(ctx, `
+		UPDATE ingredients SET stock = stock + $2, updated_at = NOW()
+		WHERE name = $1
+		RETURNING id, name, unit, stock::float8, reserved::float8, updated_at
+	`, name, quantity).Scan(&ing.ID, &ing.Name, &ing.Unit, &ing.Stock, &ing.Reserved, &ing.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, model.IngredientNotFoundError
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restock ingredient: %w", err)
+	}
+
+	ing.Available = ing.Stock - ing.Reserved
+	return &ing, nil
+}
@@ -3,8 +3,10 @@ package pg
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"restaurant-system/internal/order/model"
+	"restaurant-system/pkg/orderstatus"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -25,9 +27,9 @@ func (r *OrderRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
 func (r *OrderRepository) CreateOrder(ctx context.Context, tx pgx.Tx, order *model.Order) (int, error) {
 	query := `
 		INSERT INTO orders (
-			number, customer_name, type, table_number, delivery_address,
-			total_amount, priority, status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			number, customer_name, type, table_number, delivery_address, pickup_slot_id,
+			loyalty_id, discount_amount, total_amount, priority, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id
 	`
 
@@ -38,6 +40,9 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, tx pgx.Tx, order *mod
 		string(order.Type),
 		order.TableNumber,
 		order.DeliveryAddress,
+		order.PickupSlotID,
+		order.LoyaltyID,
+		order.DiscountAmount,
 		order.TotalAmount,
 		order.Priority,
 		string(order.Status),
@@ -116,8 +121,8 @@ func (r *OrderRepository) GetNextOrderSequence(ctx context.Context, tx pgx.Tx, d
 func (r *OrderRepository) GetOrders(ctx context.Context, page, limit int) ([]*model.Order, int, error) {
 	offset := (page - 1) * limit
 	query := `
-		SELECT id, number, customer_name, type, table_number, delivery_address, 
-			total_amount, priority, status, processed_by, completed_at, created_at, updated_at
+		SELECT id, number, customer_name, type, table_number, delivery_address, pickup_slot_id,
+			loyalty_id, discount_amount, total_amount, priority, status, processed_by, completed_at, created_at, updated_at
 		FROM orders 
 		ORDER BY created_at DESC 
 		LIMIT $1 OFFSET $2
@@ -132,8 +137,8 @@ func (r *OrderRepository) GetOrders(ctx context.Context, page, limit int) ([]*mo
 	for rows.Next() {
 		var order model.Order
 		err := rows.Scan(
-			&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber, &order.DeliveryAddress,
-			&order.TotalAmount, &order.Priority, &order.Status, &order.ProcessedBy, &order.CompletedAt, &order.CreatedAt, &order.UpdatedAt,
+			&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber, &order.DeliveryAddress, &order.PickupSlotID,
+			&order.LoyaltyID, &order.DiscountAmount, &order.TotalAmount, &order.Priority, &order.Status, &order.ProcessedBy, &order.CompletedAt, &order.CreatedAt, &order.UpdatedAt,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
@@ -153,15 +158,15 @@ func (r *OrderRepository) GetOrders(ctx context.Context, page, limit int) ([]*mo
 
 func (r *OrderRepository) GetOrder(ctx context.Context, orderNumber string) (*model.Order, error) {
 	query := `
-		SELECT id, number, customer_name, type, table_number, delivery_address, 
-			total_amount, priority, status, processed_by, completed_at, created_at, updated_at
+		SELECT id, number, customer_name, type, table_number, delivery_address, pickup_slot_id,
+			loyalty_id, discount_amount, total_amount, priority, status, processed_by, completed_at, created_at, updated_at
 		FROM orders 
 		WHERE number = $1
 	`
 	var order model.Order
 	err := r.db.QueryRow(ctx, query, orderNumber).Scan(
-		&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber, &order.DeliveryAddress,
-		&order.TotalAmount, &order.Priority, &order.Status, &order.ProcessedBy, &order.CompletedAt, &order.CreatedAt, &order.UpdatedAt,
+		&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber, &order.DeliveryAddress, &order.PickupSlotID,
+		&order.LoyaltyID, &order.DiscountAmount, &order.TotalAmount, &order.Priority, &order.Status, &order.ProcessedBy, &order.CompletedAt, &order.CreatedAt, &order.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order: %w", err)
@@ -185,3 +190,496 @@ func (r *OrderRepository) GetOrder(ctx context.Context, orderNumber string) (*mo
 
 	return &order, nil
 }
+
+func (r *OrderRepository) CountOnlineWorkers(ctx context.Context) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM workers
+		WHERE status = 'online' AND NOW() - last_seen <= INTERVAL '60 seconds'
+	`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count online workers: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountScheduledWorkers returns how many workers have a shift covering at.
+func (r *OrderRepository) CountScheduledWorkers(ctx context.Context, at time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT worker_name) FROM worker_shifts
+		WHERE starts_at <= $1 AND ends_at > $1
+	`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, at).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count scheduled workers: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *OrderRepository) GetWaitlistedOrders(ctx context.Context, limit int) ([]*model.Order, error) {
+	query := `
+		SELECT id, number, customer_name, type, table_number, delivery_address, pickup_slot_id,
+			loyalty_id, discount_amount, total_amount, priority, status, processed_by, completed_at, created_at, updated_at
+		FROM orders
+		WHERE status = 'waitlisted'
+		ORDER BY priority DESC, created_at ASC
+		LIMIT $1
+	`
+	orders, err := r.queryOrders(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waitlisted orders: %w", err)
+	}
+	return orders, nil
+}
+
+// GetWaitingOrders returns received and waitlisted orders created more than
+// olderThan ago whose priority is still below maxPriority.
+func (r *OrderRepository) GetWaitingOrders(ctx context.Context, olderThan time.Duration, maxPriority int) ([]*model.Order, error) {
+	query := `
+		SELECT id, number, customer_name, type, table_number, delivery_address, pickup_slot_id,
+			loyalty_id, discount_amount, total_amount, priority, status, processed_by, completed_at, created_at, updated_at
+		FROM orders
+		WHERE status IN ('received', 'waitlisted')
+		  AND created_at < NOW() - make_interval(secs => $1)
+		  AND priority < $2
+		ORDER BY created_at ASC
+	`
+	orders, err := r.queryOrders(ctx, query, olderThan.Seconds(), maxPriority)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waiting orders: %w", err)
+	}
+	return orders, nil
+}
+
+// MarkStaleWorkersOffline flips every worker whose heartbeat is older than
+// staleAfter to offline and returns their names.
+func (r *OrderRepository) MarkStaleWorkersOffline(ctx context.Context, staleAfter time.Duration) ([]string, error) {
+	query := `
+		UPDATE workers SET status = 'offline', in_flight_orders = '{}'
+		WHERE status <> 'offline' AND last_seen < NOW() - make_interval(secs => $1)
+		RETURNING name
+	`
+	rows, err := r.db.Query(ctx, query, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark stale workers offline: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan worker: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// GetStuckOrders returns cooking orders held by offline workers, or by workers
+// that restarted since they took the order and so no longer cook it. Orders that
+// were split into station tickets are left alone: the dispatching worker is
+// done with them once the tickets exist, and ReleaseStaleTickets recovers the
+// tickets themselves.
+func (r *OrderRepository) GetStuckOrders(ctx context.Context) ([]*model.Order, error) {
+	query := `
+		SELECT o.id, o.number, o.customer_name, o.type, o.table_number, o.delivery_address, o.pickup_slot_id,
+			o.loyalty_id, o.discount_amount, o.total_amount, o.priority, o.status, o.processed_by, o.completed_at, o.created_at, o.updated_at
+		FROM orders o
+		JOIN workers w ON w.name = o.processed_by
+		WHERE o.status = 'cooking'
+		  AND (w.status = 'offline' OR o.updated_at < w.started_at)
+		  AND NOT EXISTS (SELECT 1 FROM order_tickets t WHERE t.order_id = o.id)
+		ORDER BY o.priority DESC, o.created_at ASC
+	`
+	orders, err := r.queryOrders(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stuck orders: %w", err)
+	}
+	return orders, nil
+}
+
+// MarkOrderPublishPending flags an order that still has to be published to
+// the kitchen. The flag is set in the same transaction as the status change
+// so that a failed publish is not lost.
+func (r *OrderRepository) MarkOrderPublishPending(ctx context.Context, tx pgx.Tx, orderID int) error {
+	query := `UPDATE orders SET publish_pending = true WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, orderID); err != nil {
+		return fmt.Errorf("failed to flag order for publishing: %w", err)
+	}
+	return nil
+}
+
+// GetUnpublishedOrders returns received orders flagged for publishing.
+func (r *OrderRepository) GetUnpublishedOrders(ctx context.Context) ([]*model.Order, error) {
+	query := `
+		SELECT o.id, o.number, o.customer_name, o.type, o.table_number, o.delivery_address, o.pickup_slot_id,
+			o.loyalty_id, o.discount_amount, o.total_amount, o.priority, o.status, o.processed_by, o.completed_at, o.created_at, o.updated_at
+		FROM orders o
+		WHERE o.status = 'received' AND o.publish_pending
+		ORDER BY o.priority DESC, o.created_at ASC
+	`
+	orders, err := r.queryOrders(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unpublished orders: %w", err)
+	}
+	return orders, nil
+}
+
+func (r *OrderRepository) ClearOrderPublishPending(ctx context.Context, orderID int) error {
+	query := `UPDATE orders SET publish_pending = false WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, orderID); err != nil {
+		return fmt.Errorf("failed to clear order publish flag: %w", err)
+	}
+	return nil
+}
+
+// TryAdvisoryLock takes a session-level advisory lock on a dedicated
+// connection without waiting. When it reports true the caller must call the
+// returned function to release the lock and the connection.
+func (r *OrderRepository) TryAdvisoryLock(ctx context.Context, key int64) (func(), bool, error) {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// A fresh context so the lock is released even after shutdown began.
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			// Closing the connection ends the session and drops the lock.
+			conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+func (r *OrderRepository) queryOrders(ctx context.Context, query string, args ...interface{}) ([]*model.Order, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []*model.Order
+	for rows.Next() {
+		var order model.Order
+		err := rows.Scan(
+			&order.ID, &order.Number, &order.CustomerName, &order.Type, &order.TableNumber, &order.DeliveryAddress, &order.PickupSlotID,
+			&order.LoyaltyID, &order.DiscountAmount, &order.TotalAmount, &order.Priority, &order.Status, &order.ProcessedBy, &order.CompletedAt, &order.CreatedAt, &order.UpdatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, &order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		items, err := r.getItems(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		order.Items = items
+	}
+
+	return orders, nil
+}
+
+func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, tx pgx.Tx, orderID int, from, to model.OrderStatus) (bool, error) {
+	if err := orderstatus.Validate(string(from), string(to)); err != nil {
+		return false, err
+	}
+
+	query := `
+		UPDATE orders
+		SET status = $1, updated_at = NOW(),
+			completed_at = CASE WHEN $1 = 'completed' THEN NOW() ELSE completed_at END
+		WHERE id = $2 AND status = $3
+	`
+	tag, err := tx.Exec(ctx, query, string(to), orderID, string(from))
+	if err != nil {
+		return false, fmt.Errorf("failed to update order status: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return false, nil
+	}
+
+	if err := r.recordStage(ctx, tx, orderID, from, to); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// recordStage stores how long the order spent in the stage that the
+// from -> to transition ends, attributed to the worker who cooked it.
+func (r *OrderRepository) recordStage(ctx context.Context, tx pgx.Tx, orderID int, from, to model.OrderStatus) error {
+	stage, ok := orderstatus.Stage(string(from), string(to))
+	if !ok {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO order_stage_timings (order_id, stage, worker_name, order_type, started_at, ended_at, duration_seconds)
+		SELECT o.id, $2, o.processed_by, o.type, l.changed_at, NOW(), EXTRACT(EPOCH FROM NOW() - l.changed_at)
+		FROM orders o
+		JOIN LATERAL (
+			SELECT changed_at FROM order_status_log
+			WHERE order_id = o.id AND status = $3
+			ORDER BY changed_at DESC
+			LIMIT 1
+		) l ON true
+		WHERE o.id = $1
+		ON CONFLICT (order_id, stage) DO UPDATE SET
+			worker_name = EXCLUDED.worker_name,
+			started_at = EXCLUDED.started_at,
+			ended_at = EXCLUDED.ended_at,
+			duration_seconds = EXCLUDED.duration_seconds
+	`, orderID, stage, string(from))
+	if err != nil {
+		return fmt.Errorf("failed to record stage timing: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OrderRepository) getItems(ctx context.Context, orderID int) ([]model.OrderItem, error) {
+	itemsQuery := `SELECT id, order_id, name, quantity, price, created_at FROM order_items WHERE order_id = $1`
+	rows, err := r.db.Query(ctx, itemsQuery, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.OrderItem
+	for rows.Next() {
+		var item model.OrderItem
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.Name, &item.Quantity, &item.Price, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *OrderRepository) EnsureSlots(ctx context.Context, slots []model.PickupSlot) error {
+	query := `
+		INSERT INTO pickup_slots (slot_start, slot_end, max_orders, max_items)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (slot_start) DO NOTHING
+	`
+
+	batch := &pgx.Batch{}
+	for _, slot := range slots {
+		batch.Queue(query, slot.Start, slot.End, slot.MaxOrders, slot.MaxItems)
+	}
+
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to create pickup slots: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OrderRepository) GetSlots(ctx context.Context, from, to time.Time) ([]model.PickupSlot, error) {
+	query := `
+		SELECT id, slot_start, slot_end, max_orders, max_items, booked_orders, booked_items
+		FROM pickup_slots
+		WHERE slot_start >= $1 AND slot_start < $2
+		ORDER BY slot_start
+	`
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pickup slots: %w", err)
+	}
+	defer rows.Close()
+
+	slots := make([]model.PickupSlot, 0)
+	for rows.Next() {
+		var slot model.PickupSlot
+		err := rows.Scan(&slot.ID, &slot.Start, &slot.End, &slot.MaxOrders, &slot.MaxItems, &slot.BookedOrders, &slot.BookedItems)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pickup slot: %w", err)
+		}
+		slots = append(slots, slot)
+	}
+
+	return slots, rows.Err()
+}
+
+// ReserveSlot books capacity with a single conditional update so that the
+// row lock serialises concurrent bookings of the same slot.
+func (r *OrderRepository) ReserveSlot(ctx context.Context, tx pgx.Tx, slotID int, items int) (bool, error) {
+	query := `
+		UPDATE pickup_slots
+		SET booked_orders = booked_orders + 1, booked_items = booked_items + $2
+		WHERE id = $1
+			AND slot_start > NOW()
+			AND booked_orders + 1 <= max_orders
+			AND booked_items + $2 <= max_items
+	`
+	tag, err := tx.Exec(ctx, query, slotID, items)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve pickup slot: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *OrderRepository) UpdateOrderPriority(ctx context.Context, tx pgx.Tx, orderID int, status model.OrderStatus, priority int) (bool, error) {
+	query := `
+		UPDATE orders SET priority = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3 AND priority < $1
+	`
+	tag, err := tx.Exec(ctx, query, priority, orderID, string(status))
+	if err != nil {
+		return false, fmt.Errorf("failed to update order priority: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *OrderRepository) ReleaseSlot(ctx context.Context, tx pgx.Tx, slotID int, items int) error {
+	query := `
+		UPDATE pickup_slots
+		SET booked_orders = GREATEST(booked_orders - 1, 0), booked_items = GREATEST(booked_items - $2, 0)
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, slotID, items); err != nil {
+		return fmt.Errorf("failed to release pickup slot: %w", err)
+	}
+
+	return nil
+}
+
+// LockLoyaltyAccount serialises balance checks for one account until the
+// transaction ends.
+func (r *OrderRepository) LockLoyaltyAccount(ctx context.Context, tx pgx.Tx, loyaltyID string) error {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, loyaltyID); err != nil {
+		return fmt.Errorf("failed to lock loyalty account: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OrderRepository) GetLoyaltyBalance(ctx context.Context, tx pgx.Tx, loyaltyID string) (int, error) {
+	query := `SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE loyalty_id = $1`
+
+	var balance int
+	if err := tx.QueryRow(ctx, query, loyaltyID).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("failed to get loyalty balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (r *OrderRepository) CreateLoyaltyEntry(ctx context.Context, tx pgx.Tx, entry *model.LoyaltyEntry) (bool, error) {
+	query := `
+		INSERT INTO loyalty_ledger (loyalty_id, order_id, entry_type, points, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (order_id, entry_type) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := tx.QueryRow(ctx, query,
+		entry.LoyaltyID,
+		entry.OrderID,
+		string(entry.Type),
+		entry.Points,
+		entry.Notes,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create loyalty entry: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *OrderRepository) GetOrderLoyaltyEntries(ctx context.Context, tx pgx.Tx, orderID int) ([]model.LoyaltyEntry, error) {
+	query := `
+		SELECT id, loyalty_id, order_id, entry_type, points, notes, created_at
+		FROM loyalty_ledger WHERE order_id = $1
+	`
+	rows, err := tx.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query loyalty entries: %w", err)
+	}
+
+	return scanLoyaltyEntries(rows)
+}
+
+func (r *OrderRepository) GetLoyaltyLedger(ctx context.Context, loyaltyID string) ([]model.LoyaltyEntry, error) {
+	query := `
+		SELECT id, loyalty_id, order_id, entry_type, points, notes, created_at
+		FROM loyalty_ledger WHERE loyalty_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+	rows, err := r.db.Query(ctx, query, loyaltyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query loyalty ledger: %w", err)
+	}
+
+	return scanLoyaltyEntries(rows)
+}
+
+func scanLoyaltyEntries(rows pgx.Rows) ([]model.LoyaltyEntry, error) {
+	defer rows.Close()
+
+	entries := make([]model.LoyaltyEntry, 0)
+	for rows.Next() {
+		var entry model.LoyaltyEntry
+		err := rows.Scan(&entry.ID, &entry.LoyaltyID, &entry.OrderID, &entry.Type, &entry.Points, &entry.Notes, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan loyalty entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *OrderRepository) CreateFeedback(ctx context.Context, feedback *model.Feedback) (bool, error) {
+	query := `
+		INSERT INTO order_feedback (order_id, rating, comment, processed_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (order_id) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		feedback.OrderID,
+		feedback.Rating,
+		feedback.Comment,
+		feedback.ProcessedBy,
+	).Scan(&feedback.ID, &feedback.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create feedback: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"restaurant-system/internal/order/model"
+)
+
+// ReleaseStaleTickets hands station tickets cooked by offline or restarted
+// workers back to their station and flags them for republishing. It returns the number of
+// tickets released.
+func (r *OrderRepository) ReleaseStaleTickets(ctx context.Context) (int, error) {
+	query := `
+		UPDATE order_tickets t
+		SET status = 'pending', processed_by = NULL, claimed_at = NULL, publish_pending = true
+		FROM workers w, orders o
+		WHERE t.status = 'cooking'
+		  AND w.name = t.processed_by
+		  AND (w.status = 'offline' OR t.claimed_at < w.started_at)
+		  AND o.id = t.order_id
+		  AND o.status = 'cooking'
+	`
+	tag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release stale tickets: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetUnpublishedTickets returns pending tickets flagged for republishing
+// whose order is still cooking.
+func (r *OrderRepository) GetUnpublishedTickets(ctx context.Context) ([]*model.Ticket, error) {
+	query := `
+		SELECT t.id, o.number, o.type, o.priority, t.station, t.items
+		FROM order_tickets t
+		JOIN orders o ON o.id = t.order_id
+		WHERE t.publish_pending AND t.status = 'pending' AND o.status = 'cooking'
+		ORDER BY o.priority DESC, t.id ASC
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unpublished tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []*model.Ticket
+	for rows.Next() {
+		var ticket model.Ticket
+		var items []byte
+		if err := rows.Scan(&ticket.ID, &ticket.OrderNumber, &ticket.OrderType, &ticket.Priority, &ticket.Station, &items); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		if err := json.Unmarshal(items, &ticket.Items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ticket items: %w", err)
+		}
+		tickets = append(tickets, &ticket)
+	}
+	return tickets, rows.Err()
+}
+
+func (r *OrderRepository) ClearTicketPublishPending(ctx context.Context, ticketID int) error {
+	query := `UPDATE order_tickets SET publish_pending = false WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, ticketID); err != nil {
+		return fmt.Errorf("failed to clear ticket publish flag: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package rmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"restaurant-system/pkg/rabbitmq"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type BacklogInspector struct {
+	rabbitmq *rabbitmq.RabbitMQ
+	queues   []string
+}
+
+func NewBacklogInspector(rabbitmq *rabbitmq.RabbitMQ, queues []string) *BacklogInspector {
+	return &BacklogInspector{rabbitmq: rabbitmq, queues: queues}
+}
+
+func (b *BacklogInspector) QueueDepth(ctx context.Context) (int, error) {
+	total := 0
+	for _, name := range b.queues {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		queue, err := b.rabbitmq.InspectQueue(name)
+		if err != nil {
+			var amqpErr *amqp091.Error
+			if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.NotFound {
+				continue
+			}
+			return 0, fmt.Errorf("failed to inspect queue %s: %w", name, err)
+		}
+		total += queue.Messages
+	}
+
+	return total, nil
+}
@@ -1,6 +1,10 @@
 package rmq
 
-import "restaurant-system/internal/order/model"
+import (
+	"time"
+
+	"restaurant-system/internal/order/model"
+)
 
 type OrderMessage struct {
 	OrderNumber     string            `json:"order_number"`
@@ -13,3 +17,21 @@ type OrderMessage struct {
 	Priority        int               `json:"priority"`
 	DeliveryTag     uint64            `json:"-"`
 }
+
+type TicketMessage struct {
+	TicketID    int                `json:"ticket_id"`
+	OrderNumber string             `json:"order_number"`
+	OrderType   string             `json:"order_type"`
+	Station     string             `json:"station"`
+	Items       []model.TicketItem `json:"items"`
+	Priority    int                `json:"priority"`
+}
+
+type StatusUpdateMessage struct {
+	OrderNumber         string    `json:"order_number"`
+	OldStatus           string    `json:"old_status"`
+	NewStatus           string    `json:"new_status"`
+	ChangedBy           string    `json:"changed_by"`
+	Timestamp           time.Time `json:"timestamp"`
+	EstimatedCompletion time.Time `json:"estimated_completion"`
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"restaurant-system/internal/order/model"
 	"restaurant-system/pkg/rabbitmq"
@@ -31,6 +32,19 @@ func NewOrderPublisher(rabbitmq *rabbitmq.RabbitMQ) (*OrderPublisher, error) {
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
+	err = ch.ExchangeDeclare(
+		"notifications_fanout",
+		"fanout",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
 	return &OrderPublisher{rabbitmq: rabbitmq}, nil
 }
 
@@ -70,3 +84,73 @@ func (p *OrderPublisher) PublishCreatedOrder(ctx context.Context, order *model.O
 
 	return nil
 }
+
+// PublishTicket sends a station ticket back to its station queue, using the
+// same routing key the kitchen uses when it splits an order.
+func (p *OrderPublisher) PublishTicket(ctx context.Context, ticket *model.Ticket) error {
+	message := TicketMessage{
+		TicketID:    ticket.ID,
+		OrderNumber: ticket.OrderNumber,
+		OrderType:   string(ticket.OrderType),
+		Station:     ticket.Station,
+		Items:       ticket.Items,
+		Priority:    ticket.Priority,
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket message: %w", err)
+	}
+
+	routingKey := fmt.Sprintf("station.%s.%s", ticket.Station, ticket.OrderType)
+
+	err = p.rabbitmq.Channel().PublishWithContext(ctx,
+		"orders_topic",
+		routingKey,
+		false,
+		false,
+		amqp091.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp091.Persistent,
+			Priority:     uint8(ticket.Priority),
+		})
+	if err != nil {
+		return fmt.Errorf("failed to publish ticket: %w", err)
+	}
+
+	return nil
+}
+
+func (p *OrderPublisher) PublishStatusChange(ctx context.Context, order *model.Order, oldStatus model.OrderStatus, changedBy string) error {
+	now := time.Now()
+	update := StatusUpdateMessage{
+		OrderNumber:         order.Number,
+		OldStatus:           string(oldStatus),
+		NewStatus:           string(order.Status),
+		ChangedBy:           changedBy,
+		Timestamp:           now,
+		EstimatedCompletion: now,
+	}
+
+	body, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status update: %w", err)
+	}
+
+	err = p.rabbitmq.Channel().PublishWithContext(ctx,
+		"notifications_fanout",
+		"",
+		false,
+		false,
+		amqp091.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp091.Persistent,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to publish status update: %w", err)
+	}
+
+	return nil
+}
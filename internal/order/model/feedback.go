@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type Feedback struct {
+	ID          int       `json:"id"`
+	OrderID     int       `json:"order_id"`
+	Rating      int       `json:"rating"`
+	Comment     *string   `json:"comment,omitempty"`
+	ProcessedBy *string   `json:"processed_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type FeedbackRequest struct {
+	Rating  int     `json:"rating"`
+	Comment *string `json:"comment,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Ingredient struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Unit      string    `json:"unit"`
+	Stock     float64   `json:"stock"`
+	Reserved  float64   `json:"reserved"`
+	Available float64   `json:"available"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Inventory struct {
+	Ingredients      []Ingredient `json:"ingredients"`
+	UnavailableItems []string     `json:"unavailable_items"`
+}
+
+type RestockRequest struct {
+	Quantity float64 `json:"quantity"`
+}
+
+func (r *RestockRequest) Validate() error {
+	if r.Quantity <= 0 {
+		return fmt.Errorf("%w: quantity must be positive", ValidationError)
+	}
+	return nil
+}
+
+// RecipeName is the key menu items are looked up by in the recipes table.
+func RecipeName(itemName string) string {
+	return strings.ToLower(strings.TrimSpace(itemName))
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type LoyaltyEntryType string
+
+const (
+	LoyaltyEarn          LoyaltyEntryType = "earn"
+	LoyaltyRedeem        LoyaltyEntryType = "redeem"
+	LoyaltyReverseEarn   LoyaltyEntryType = "reverse_earn"
+	LoyaltyReverseRedeem LoyaltyEntryType = "reverse_redeem"
+)
+
+type LoyaltyEntry struct {
+	ID        int              `json:"id"`
+	LoyaltyID string           `json:"loyalty_id"`
+	OrderID   *int             `json:"order_id,omitempty"`
+	Type      LoyaltyEntryType `json:"entry_type"`
+	Points    int              `json:"points"`
+	Notes     *string          `json:"notes,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type LoyaltyAccount struct {
+	LoyaltyID string         `json:"loyalty_id"`
+	Balance   int            `json:"balance"`
+	Entries   []LoyaltyEntry `json:"entries"`
+}
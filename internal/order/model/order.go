@@ -12,14 +12,18 @@ const (
 	OrderTypeDelivery OrderType = "delivery"
 )
 
+const MaxPriority = 10
+
 type OrderStatus string
 
 const (
-	StatusReceived  OrderStatus = "received"
-	StatusCooking   OrderStatus = "cooking"
-	StatusReady     OrderStatus = "ready"
-	StatusCompleted OrderStatus = "completed"
-	StatusCancelled OrderStatus = "cancelled"
+	StatusWaitlisted OrderStatus = "waitlisted"
+	StatusReceived   OrderStatus = "received"
+	StatusCooking    OrderStatus = "cooking"
+	StatusReady      OrderStatus = "ready"
+	StatusCompleted  OrderStatus = "completed"
+	StatusCancelled  OrderStatus = "cancelled"
+	StatusRefunded   OrderStatus = "refunded"
 )
 
 type Order struct {
@@ -29,6 +33,9 @@ type Order struct {
 	Type            OrderType   `json:"type"`
 	TableNumber     *int        `json:"table_number,omitempty"`
 	DeliveryAddress *string     `json:"delivery_address,omitempty"`
+	PickupSlotID    *int        `json:"pickup_slot_id,omitempty"`
+	LoyaltyID       *string     `json:"loyalty_id,omitempty"`
+	DiscountAmount  float64     `json:"discount_amount"`
 	TotalAmount     float64     `json:"total_amount"`
 	Priority        int         `json:"priority"`
 	Status          OrderStatus `json:"status"`
@@ -37,6 +44,9 @@ type Order struct {
 	CreatedAt       time.Time   `json:"created_at"`
 	UpdatedAt       time.Time   `json:"updated_at"`
 	Items           []OrderItem `json:"items"`
+
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"`
+	RedeemPoints        int        `json:"-"`
 }
 
 type OrderItem struct {
@@ -62,11 +72,53 @@ type CreateOrderRequest struct {
 	OrderType       OrderType          `json:"order_type"`
 	TableNumber     *int               `json:"table_number,omitempty"`
 	DeliveryAddress *string            `json:"delivery_address,omitempty"`
+	PickupSlotID    *int               `json:"pickup_slot_id,omitempty"`
+	LoyaltyID       *string            `json:"loyalty_id,omitempty"`
+	RedeemPoints    int                `json:"redeem_points,omitempty"`
 	Items           []OrderItemRequest `json:"items"`
 }
 
+type EscalateOrderRequest struct {
+	Priority    int    `json:"priority"`
+	EscalatedBy string `json:"escalated_by"`
+	Reason      string `json:"reason"`
+}
+
+type ChangeStatusRequest struct {
+	ChangedBy string `json:"changed_by"`
+	Reason    string `json:"reason"`
+}
+
 type OrderItemRequest struct {
 	Name     string  `json:"name"`
 	Quantity int     `json:"quantity"`
 	Price    float64 `json:"price"`
 }
+
+type AdmissionMode string
+
+const (
+	AdmissionReject    AdmissionMode = "reject"
+	AdmissionExtendETA AdmissionMode = "extend_eta"
+	AdmissionWaitlist  AdmissionMode = "waitlist"
+)
+
+// KitchenLoad compares the kitchen backlog with the staff expected to work it
+// off. Staff counts scheduled workers as well as those sending heartbeats.
+type KitchenLoad struct {
+	QueuedOrders     int `json:"queued_orders"`
+	OnlineWorkers    int `json:"online_workers"`
+	ScheduledWorkers int `json:"scheduled_workers"`
+	Staff            int `json:"staff"`
+	Capacity         int `json:"capacity"`
+}
+
+// Overloaded reports whether the backlog has reached capacity. Zero capacity
+// means nobody is there to cook, so the kitchen is overloaded even with an
+// empty queue.
+func (l KitchenLoad) Overloaded() bool {
+	if l.Capacity <= 0 {
+		return true
+	}
+	return l.QueuedOrders >= l.Capacity
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type PickupSlot struct {
+	ID           int       `json:"id"`
+	Start        time.Time `json:"slot_start"`
+	End          time.Time `json:"slot_end"`
+	MaxOrders    int       `json:"max_orders"`
+	MaxItems     int       `json:"max_items"`
+	BookedOrders int       `json:"booked_orders"`
+	BookedItems  int       `json:"booked_items"`
+}
+
+func (s PickupSlot) Available(now time.Time) bool {
+	return s.Start.After(now) && s.BookedOrders < s.MaxOrders && s.BookedItems < s.MaxItems
+}
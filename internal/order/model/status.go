@@ -0,0 +1,25 @@
+package model
+
+import "restaurant-system/pkg/orderstatus"
+
+// manualTransitions lists the status changes staff can make through the
+// order service; kitchen transitions are owned by the kitchen worker.
+// Every entry must also be allowed by orderstatus.
+var manualTransitions = map[OrderStatus][]OrderStatus{
+	StatusWaitlisted: {StatusCancelled},
+	StatusReceived:   {StatusCancelled},
+	StatusReady:      {StatusCompleted},
+	StatusCompleted:  {StatusRefunded},
+}
+
+func CanTransitionManually(from, to OrderStatus) bool {
+	if !orderstatus.CanTransition(string(from), string(to)) {
+		return false
+	}
+	for _, allowed := range manualTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
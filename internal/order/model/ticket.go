@@ -0,0 +1,15 @@
+package model
+
+type Ticket struct {
+	ID          int          `json:"id"`
+	OrderNumber string       `json:"order_number"`
+	OrderType   OrderType    `json:"order_type"`
+	Priority    int          `json:"priority"`
+	Station     string       `json:"station"`
+	Items       []TicketItem `json:"items"`
+}
+
+type TicketItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
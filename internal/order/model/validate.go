@@ -8,7 +8,17 @@ import (
 	"unicode/utf8"
 )
 
-var ValidationError = errors.New("validation error")
+var (
+	ValidationError         = errors.New("validation error")
+	KitchenOverloadedError  = errors.New("kitchen is at capacity")
+	SlotUnavailableError    = errors.New("pickup slot is unavailable")
+	OrderNotFoundError      = errors.New("order not found")
+	OrderStateError         = errors.New("order cannot be changed in its current status")
+	InsufficientPointsError = errors.New("insufficient loyalty points")
+	FeedbackExistsError     = errors.New("feedback already submitted")
+	OutOfStockError         = errors.New("not enough stock for this order")
+	IngredientNotFoundError = errors.New("ingredient not found")
+)
 
 func (o *Order) Validate() error {
 	var validationErrors []string
@@ -57,6 +67,23 @@ func (o *Order) Validate() error {
 		}
 	}
 
+	if o.PickupSlotID != nil && o.Type != OrderTypeTakeout {
+		validationErrors = append(validationErrors, "pickup_slot_id is only allowed for takeout orders")
+	}
+
+	if o.LoyaltyID != nil {
+		if strings.TrimSpace(*o.LoyaltyID) == "" {
+			validationErrors = append(validationErrors, "loyalty_id must not be empty")
+		} else if utf8.RuneCountInString(*o.LoyaltyID) > 100 {
+			validationErrors = append(validationErrors, "loyalty_id must be 100 characters or less")
+		}
+	}
+	if o.RedeemPoints < 0 {
+		validationErrors = append(validationErrors, "redeem_points must not be negative")
+	} else if o.RedeemPoints > 0 && o.LoyaltyID == nil {
+		validationErrors = append(validationErrors, "loyalty_id is required to redeem points")
+	}
+
 	if len(o.Items) == 0 {
 		validationErrors = append(validationErrors, "items must contain at least 1 item")
 	} else if len(o.Items) > 20 {
@@ -86,6 +113,47 @@ func (o *Order) Validate() error {
 	return nil
 }
 
+func (r *EscalateOrderRequest) Validate(currentPriority int) error {
+	var validationErrors []string
+
+	if r.Priority < 1 || r.Priority > MaxPriority {
+		validationErrors = append(validationErrors, fmt.Sprintf("priority must be between 1 and %d", MaxPriority))
+	} else if r.Priority <= currentPriority {
+		validationErrors = append(validationErrors, fmt.Sprintf("priority must be higher than the current priority %d", currentPriority))
+	}
+	if strings.TrimSpace(r.EscalatedBy) == "" {
+		validationErrors = append(validationErrors, "escalated_by is required")
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		validationErrors = append(validationErrors, "reason is required")
+	} else if utf8.RuneCountInString(r.Reason) > 500 {
+		validationErrors = append(validationErrors, "reason must be 500 characters or less")
+	}
+
+	if len(validationErrors) > 0 {
+		return fmt.Errorf("%w: %s", ValidationError, strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
+
+func (r *FeedbackRequest) Validate() error {
+	var validationErrors []string
+
+	if r.Rating < 1 || r.Rating > 5 {
+		validationErrors = append(validationErrors, "rating must be between 1 and 5")
+	}
+	if r.Comment != nil && utf8.RuneCountInString(*r.Comment) > 1000 {
+		validationErrors = append(validationErrors, "comment must be 1000 characters or less")
+	}
+
+	if len(validationErrors) > 0 {
+		return fmt.Errorf("%w: %s", ValidationError, strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
+
 func isValidName(name string) bool {
 	match, _ := regexp.MatchString(`^[a-zA-Z\s\-']+$`, name)
 	return match
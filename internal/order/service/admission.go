@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant-system/internal/order/model"
+	"restaurant-system/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// defaultOrdersPerWorker applies when admission.orders_per_worker is unset,
+// which would otherwise leave the kitchen with no capacity at all.
+const defaultOrdersPerWorker = 5
+
+func (s *OrderService) kitchenLoad(ctx context.Context) (model.KitchenLoad, error) {
+	queued, err := s.backlog.QueueDepth(ctx)
+	if err != nil {
+		return model.KitchenLoad{}, fmt.Errorf("failed to get queue depth: %w", err)
+	}
+
+	online, err := s.repo.CountOnlineWorkers(ctx)
+	if err != nil {
+		return model.KitchenLoad{}, err
+	}
+
+	scheduled, err := s.repo.CountScheduledWorkers(ctx, time.Now())
+	if err != nil {
+		return model.KitchenLoad{}, err
+	}
+
+	// Staff is the larger of the two counts, uncapped: a scheduled worker
+	// that is between restarts keeps its share of the capacity, at the cost
+	// of over-admitting while a scheduled worker is absent. Nobody online
+	// still means no capacity whatever the rota says.
+	staff := online
+	if online > 0 {
+		staff = max(online, scheduled)
+	}
+
+	perWorker := s.admission.OrdersPerWorker
+	if perWorker <= 0 {
+		perWorker = defaultOrdersPerWorker
+	}
+
+	return model.KitchenLoad{
+		QueuedOrders:     queued,
+		OnlineWorkers:    online,
+		ScheduledWorkers: scheduled,
+		Staff:            staff,
+		Capacity:         staff * perWorker,
+	}, nil
+}
+
+// quoteETA adds the configured per-order delay for every queued order beyond
+// what the staff can absorb.
+func (s *OrderService) quoteETA(load model.KitchenLoad) time.Time {
+	eta := time.Duration(s.admission.BaseETAMinutes) * time.Minute
+
+	excess := load.QueuedOrders - load.Capacity
+	if load.Staff == 0 {
+		excess = load.QueuedOrders + 1
+	}
+	if excess > 0 {
+		workers := max(load.Staff, 1)
+		eta += time.Duration(excess*s.admission.MinutesPerQueuedOrder/workers) * time.Minute
+	}
+
+	return time.Now().Add(eta)
+}
+
+func (s *OrderService) ReleaseWaitlist(ctx context.Context) (int, error) {
+	rid := fmt.Sprintf("waitlist-%d", time.Now().UnixNano())
+
+	load, err := s.kitchenLoad(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	free := load.Capacity - load.QueuedOrders
+	if free <= 0 {
+		return 0, nil
+	}
+
+	orders, err := s.repo.GetWaitlistedOrders(ctx, free)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, order := range orders {
+		ok, err := s.releaseOrder(ctx, order)
+		if err != nil {
+			logger.Log(logger.ERROR, "order-service", "waitlist_release_failed", "failed to release waitlisted order", rid,
+				map[string]interface{}{"order_number": order.Number}, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err := s.rmq.PublishCreatedOrder(ctx, order); err != nil {
+			logger.Log(logger.ERROR, "order-service", "rabbitmq_publish_failed", "failed to publish released order", rid,
+				map[string]interface{}{"order_number": order.Number, "priority": order.Priority}, err)
+			continue
+		}
+
+		released++
+		logger.Log(logger.DEBUG, "order-service", "order_released", "waitlisted order released to kitchen", rid,
+			map[string]interface{}{"order_number": order.Number, "priority": order.Priority}, nil)
+	}
+
+	return released, nil
+}
+
+func (s *OrderService) releaseOrder(ctx context.Context, order *model.Order) (bool, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			logger.Log(logger.ERROR, "order-service", "db_rollback_failed", "failed to rollback transaction", "",
+				map[string]interface{}{"error": err.Error()}, err)
+		}
+	}()
+
+	ok, err := s.repo.UpdateOrderStatus(ctx, tx, order.ID, model.StatusWaitlisted, model.StatusReceived)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	notes := "released from waitlist"
+	_, err = s.repo.CreateLog(ctx, tx, &model.OrderStatusLog{
+		OrderID:   order.ID,
+		Status:    model.StatusReceived,
+		ChangedBy: "system",
+		ChangedAt: time.Now(),
+		Notes:     &notes,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	order.Status = model.StatusReceived
+	return true, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant-system/config"
+	"restaurant-system/internal/order/model"
+	"restaurant-system/pkg/logger"
+)
+
+const agingName = "aging"
+
+// agedPriority returns the highest priority any step grants to an order that
+// has waited for the given time, or 0 when no step applies yet.
+func agedPriority(steps []config.AgingStep, waited time.Duration) int {
+	priority := 0
+	for _, step := range steps {
+		if waited >= time.Duration(step.AfterMinutes)*time.Minute {
+			priority = max(priority, min(step.Priority, model.MaxPriority))
+		}
+	}
+	return priority
+}
+
+// AgeOrders raises the priority of orders that have been waiting past the
+// configured thresholds. Received orders are republished at the new priority;
+// the kitchen drops the lower-priority copies as they arrive.
+func (s *OrderService) AgeOrders(ctx context.Context, steps []config.AgingStep) (int, error) {
+	if len(steps) == 0 {
+		return 0, nil
+	}
+
+	rid := fmt.Sprintf("aging-%d", time.Now().UnixNano())
+
+	threshold, top := steps[0].AfterMinutes, 0
+	for _, step := range steps {
+		threshold = min(threshold, step.AfterMinutes)
+		top = max(top, min(step.Priority, model.MaxPriority))
+	}
+
+	orders, err := s.repo.GetWaitingOrders(ctx, time.Duration(threshold)*time.Minute, top)
+	if err != nil {
+		return 0, err
+	}
+
+	aged := 0
+	now := time.Now()
+	for _, order := range orders {
+		waited := now.Sub(order.CreatedAt)
+		priority := agedPriority(steps, waited)
+		if priority <= order.Priority {
+			continue
+		}
+
+		oldPriority := order.Priority
+		notes := fmt.Sprintf("priority aged from %d to %d after waiting %s", oldPriority, priority, waited.Truncate(time.Second))
+		if err := s.raisePriority(ctx, order, priority, agingName, notes); err != nil {
+			logger.Log(logger.ERROR, "order-service", "order_aging_failed", "failed to age order priority", rid,
+				map[string]interface{}{"order_number": order.Number}, err)
+			continue
+		}
+
+		aged++
+		logger.Log(logger.DEBUG, "order-service", "order_aged", "order priority aged", rid,
+			map[string]interface{}{
+				"order_number": order.Number,
+				"old_priority": oldPriority,
+				"new_priority": priority,
+				"waited":       waited.Truncate(time.Second).String(),
+			}, nil)
+
+		if order.Status == model.StatusWaitlisted {
+			continue
+		}
+
+		s.publishPendingOrder(ctx, order, rid, "failed to republish aged order")
+	}
+
+	return aged, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"restaurant-system/config"
+	"restaurant-system/internal/order/model"
+)
+
+func TestAgedPriority(t *testing.T) {
+	steps := []config.AgingStep{
+		{AfterMinutes: 5, Priority: 5},
+		{AfterMinutes: 10, Priority: 8},
+		{AfterMinutes: 15, Priority: 10},
+	}
+
+	tests := []struct {
+		name   string
+		steps  []config.AgingStep
+		waited time.Duration
+		want   int
+	}{
+		{"no steps", nil, time.Hour, 0},
+		{"before first step", steps, 4 * time.Minute, 0},
+		{"at first step", steps, 5 * time.Minute, 5},
+		{"between steps", steps, 12 * time.Minute, 8},
+		{"at last step", steps, 15 * time.Minute, 10},
+		{"long past last step", steps, 2 * time.Hour, 10},
+		{
+			name:   "unordered steps",
+			steps:  []config.AgingStep{{AfterMinutes: 15, Priority: 10}, {AfterMinutes: 5, Priority: 5}},
+			waited: 20 * time.Minute,
+			want:   10,
+		},
+		{
+			name:   "later step with lower priority",
+			steps:  []config.AgingStep{{AfterMinutes: 5, Priority: 8}, {AfterMinutes: 10, Priority: 3}},
+			waited: 12 * time.Minute,
+			want:   8,
+		},
+		{
+			name:   "capped at max priority",
+			steps:  []config.AgingStep{{AfterMinutes: 1, Priority: 99}},
+			waited: 2 * time.Minute,
+			want:   model.MaxPriority,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := agedPriority(tt.steps, tt.waited); got != tt.want {
+				t.Errorf("agedPriority() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"restaurant-system/internal/order/model"
+	"restaurant-system/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *OrderService) EscalateOrder(ctx context.Context, orderNumber string, req *model.EscalateOrderRequest) (*model.Order, error) {
+	rid := ""
+	if v := ctx.Value("request_id"); v != nil {
+		if str, ok := v.(string); ok {
+			rid = str
+		}
+	}
+
+	order, err := s.repo.GetOrder(ctx, orderNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.OrderNotFoundError
+		}
+		return nil, err
+	}
+
+	if order.Status != model.StatusReceived && order.Status != model.StatusWaitlisted {
+		return nil, fmt.Errorf("%w: %s", model.OrderStateError, order.Status)
+	}
+
+	if err := req.Validate(order.Priority); err != nil {
+		logger.Log(logger.ERROR, "order-service", "validation_failed", "escalation validation failed", rid,
+			map[string]interface{}{"order_number": orderNumber, "error": err.Error()}, err)
+		return nil, err
+	}
+
+	oldPriority := order.Priority
+	notes := fmt.Sprintf("priority escalated from %d to %d: %s", oldPriority, req.Priority, req.Reason)
+	if err := s.raisePriority(ctx, order, req.Priority, req.EscalatedBy, notes); err != nil {
+		return nil, err
+	}
+
+	logger.Log(logger.INFO, "order-service", "order_escalated", "order priority escalated", rid,
+		map[string]interface{}{
+			"order_number": order.Number,
+			"old_priority": oldPriority,
+			"new_priority": order.Priority,
+			"escalated_by": req.EscalatedBy,
+		}, nil)
+
+	// Waitlisted orders are picked up by priority when the waitlist is released.
+	if order.Status == model.StatusWaitlisted {
+		return order, nil
+	}
+
+	// The original message stays queued; the kitchen drops it as superseded
+	// by the escalated copy. A failed publish is
+	// left to the reaper, since the escalation itself is already stored.
+	s.publishPendingOrder(ctx, order, rid, "failed to republish escalated order")
+
+	return order, nil
+}
+
+// raisePriority stores the new priority and logs the change with notes in
+// the same transaction. Received orders are flagged for publishing so the
+// reaper republishes them if the caller's publish fails.
+func (s *OrderService) raisePriority(ctx context.Context, order *model.Order, priority int, changedBy, notes string) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			logger.Log(logger.ERROR, "order-service", "db_rollback_failed", "failed to rollback transaction", "",
+				map[string]interface{}{"error": err.Error()}, err)
+		}
+	}()
+
+	updated, err := s.repo.UpdateOrderPriority(ctx, tx, order.ID, order.Status, priority)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return fmt.Errorf("%w: order changed concurrently", model.OrderStateError)
+	}
+
+	_, err = s.repo.CreateLog(ctx, tx, &model.OrderStatusLog{
+		OrderID:   order.ID,
+		Status:    order.Status,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now(),
+		Notes:     &notes,
+	})
+	if err != nil {
+		return err
+	}
+
+	if order.Status == model.StatusReceived {
+		if err := s.repo.MarkOrderPublishPending(ctx, tx, order.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	order.Priority = priority
+	return nil
+}
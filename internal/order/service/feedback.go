@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"restaurant-system/internal/order/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *OrderService) SubmitFeedback(ctx context.Context, orderNumber string, req *model.FeedbackRequest) (*model.Feedback, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.GetOrder(ctx, orderNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.OrderNotFoundError
+		}
+		return nil, err
+	}
+
+	if order.Status != model.StatusCompleted {
+		return nil, fmt.Errorf("%w: feedback is accepted only for completed orders", model.OrderStateError)
+	}
+
+	feedback := &model.Feedback{
+		OrderID:     order.ID,
+		Rating:      req.Rating,
+		Comment:     req.Comment,
+		ProcessedBy: order.ProcessedBy,
+	}
+
+	created, err := s.repo.CreateFeedback(ctx, feedback)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, model.FeedbackExistsError
+	}
+
+	return feedback, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restaurant-system/internal/order/model"
+	"restaurant-system/pkg/logger"
+)
+
+// checkAvailability rejects orders for menu items that have been 86'd
+// because one of their ingredients ran out.
+func (s *OrderService) checkAvailability(ctx context.Context, items []model.OrderItem) error {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = model.RecipeName(item.Name)
+	}
+
+	unavailable, err := s.repo.GetUnavailableItems(ctx, names)
+	if err != nil {
+		return err
+	}
+	if len(unavailable) > 0 {
+		return fmt.Errorf("%w: unavailable items: %s", model.ValidationError, strings.Join(unavailable, ", "))
+	}
+
+	return nil
+}
+
+func (s *OrderService) GetInventory(ctx context.Context) (*model.Inventory, error) {
+	ingredients, err := s.repo.GetIngredients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unavailable, err := s.repo.GetUnavailableItems(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Inventory{Ingredients: ingredients, UnavailableItems: unavailable}, nil
+}
+
+func (s *OrderService) Restock(ctx context.Context, ingredient string, req *model.RestockRequest) (*model.Ingredient, error) {
+	rid := ""
+	if v := ctx.Value("request_id"); v != nil {
+		if str, ok := v.(string); ok {
+			rid = str
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ing, err := s.repo.Restock(ctx, ingredient, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log(logger.INFO, "order-service", "ingredient_restocked", "ingredient restocked", rid,
+		map[string]interface{}{
+			"ingredient": ing.Name,
+			"quantity":   req.Quantity,
+			"available":  ing.Available,
+		}, nil)
+
+	return ing, nil
+}
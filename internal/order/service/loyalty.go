@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"restaurant-system/internal/order/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *OrderService) GetLoyaltyAccount(ctx context.Context, loyaltyID string) (*model.LoyaltyAccount, error) {
+	entries, err := s.repo.GetLoyaltyLedger(ctx, loyaltyID)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &model.LoyaltyAccount{LoyaltyID: loyaltyID, Entries: entries}
+	for _, entry := range entries {
+		account.Balance += entry.Points
+	}
+
+	return account, nil
+}
+
+// applyRedemption checks the balance under an account lock and converts the
+// requested points into a discount on the order total.
+func (s *OrderService) applyRedemption(ctx context.Context, tx pgx.Tx, order *model.Order) error {
+	if !s.loyalty.Enabled {
+		return fmt.Errorf("%w: loyalty program is disabled", model.ValidationError)
+	}
+
+	discount := roundAmount(float64(order.RedeemPoints) * s.loyalty.PointValue)
+	maxDiscount := roundAmount(order.TotalAmount * s.loyalty.MaxRedeemPercent / 100)
+	if discount > maxDiscount {
+		return fmt.Errorf("%w: discount %.2f exceeds the maximum of %.2f", model.ValidationError, discount, maxDiscount)
+	}
+
+	if err := s.repo.LockLoyaltyAccount(ctx, tx, *order.LoyaltyID); err != nil {
+		return err
+	}
+
+	balance, err := s.repo.GetLoyaltyBalance(ctx, tx, *order.LoyaltyID)
+	if err != nil {
+		return err
+	}
+	if balance < order.RedeemPoints {
+		return fmt.Errorf("%w: balance %d, requested %d", model.InsufficientPointsError, balance, order.RedeemPoints)
+	}
+
+	order.DiscountAmount = discount
+	order.TotalAmount = roundAmount(order.TotalAmount - discount)
+
+	return nil
+}
+
+func (s *OrderService) earnPoints(order *model.Order) int {
+	var points float64
+	for _, rule := range s.loyalty.EarnRules {
+		if rule.OrderType != "" && rule.OrderType != string(order.Type) {
+			continue
+		}
+		if order.TotalAmount < rule.MinTotal {
+			continue
+		}
+		points += order.TotalAmount*rule.PointsPerUnit + float64(rule.BonusPoints)
+	}
+
+	return int(math.Floor(points))
+}
+
+func (s *OrderService) awardPoints(ctx context.Context, tx pgx.Tx, order *model.Order) error {
+	if !s.loyalty.Enabled || order.LoyaltyID == nil {
+		return nil
+	}
+
+	points := s.earnPoints(order)
+	if points <= 0 {
+		return nil
+	}
+
+	notes := fmt.Sprintf("earned for order %s", order.Number)
+	_, err := s.repo.CreateLoyaltyEntry(ctx, tx, &model.LoyaltyEntry{
+		LoyaltyID: *order.LoyaltyID,
+		OrderID:   &order.ID,
+		Type:      model.LoyaltyEarn,
+		Points:    points,
+		Notes:     &notes,
+	})
+	return err
+}
+
+// reversePoints offsets every earn and redeem entry of the order. The unique
+// (order_id, entry_type) constraint makes repeated reversals no-ops.
+func (s *OrderService) reversePoints(ctx context.Context, tx pgx.Tx, order *model.Order) error {
+	if order.LoyaltyID == nil {
+		return nil
+	}
+
+	entries, err := s.repo.GetOrderLoyaltyEntries(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		var reverseType model.LoyaltyEntryType
+		switch entry.Type {
+		case model.LoyaltyEarn:
+			reverseType = model.LoyaltyReverseEarn
+		case model.LoyaltyRedeem:
+			reverseType = model.LoyaltyReverseRedeem
+		default:
+			continue
+		}
+
+		notes := fmt.Sprintf("reversed %s for order %s (%s)", entry.Type, order.Number, order.Status)
+		_, err := s.repo.CreateLoyaltyEntry(ctx, tx, &model.LoyaltyEntry{
+			LoyaltyID: entry.LoyaltyID,
+			OrderID:   &order.ID,
+			Type:      reverseType,
+			Points:    -entry.Points,
+			Notes:     &notes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"restaurant-system/config"
 	"restaurant-system/internal/order/model"
 	"restaurant-system/pkg/logger"
 
@@ -19,19 +20,59 @@ type OrderRepository interface {
 	GetNextOrderSequence(ctx context.Context, tx pgx.Tx, date string) (int, error)
 	GetOrders(ctx context.Context, page, limit int) ([]*model.Order, int, error)
 	GetOrder(ctx context.Context, orderNumber string) (*model.Order, error)
+	CountOnlineWorkers(ctx context.Context) (int, error)
+	CountScheduledWorkers(ctx context.Context, at time.Time) (int, error)
+	GetWaitlistedOrders(ctx context.Context, limit int) ([]*model.Order, error)
+	GetWaitingOrders(ctx context.Context, olderThan time.Duration, maxPriority int) ([]*model.Order, error)
+	MarkStaleWorkersOffline(ctx context.Context, staleAfter time.Duration) ([]string, error)
+	GetStuckOrders(ctx context.Context) ([]*model.Order, error)
+	UpdateOrderStatus(ctx context.Context, tx pgx.Tx, orderID int, from, to model.OrderStatus) (bool, error)
+	EnsureSlots(ctx context.Context, slots []model.PickupSlot) error
+	GetSlots(ctx context.Context, from, to time.Time) ([]model.PickupSlot, error)
+	ReserveSlot(ctx context.Context, tx pgx.Tx, slotID int, items int) (bool, error)
+	UpdateOrderPriority(ctx context.Context, tx pgx.Tx, orderID int, status model.OrderStatus, priority int) (bool, error)
+	ReleaseSlot(ctx context.Context, tx pgx.Tx, slotID int, items int) error
+	LockLoyaltyAccount(ctx context.Context, tx pgx.Tx, loyaltyID string) error
+	GetLoyaltyBalance(ctx context.Context, tx pgx.Tx, loyaltyID string) (int, error)
+	CreateLoyaltyEntry(ctx context.Context, tx pgx.Tx, entry *model.LoyaltyEntry) (bool, error)
+	GetOrderLoyaltyEntries(ctx context.Context, tx pgx.Tx, orderID int) ([]model.LoyaltyEntry, error)
+	GetLoyaltyLedger(ctx context.Context, loyaltyID string) ([]model.LoyaltyEntry, error)
+	CreateFeedback(ctx context.Context, feedback *model.Feedback) (bool, error)
+	GetUnavailableItems(ctx context.Context, names []string) ([]string, error)
+	ReserveStock(ctx context.Context, tx pgx.Tx, orderID int, items []model.OrderItem) (bool, error)
+	ReleaseStock(ctx context.Context, tx pgx.Tx, orderID int) error
+	GetIngredients(ctx context.Context) ([]model.Ingredient, error)
+	Restock(ctx context.Context, name string, quantity float64) (*model.Ingredient, error)
+	MarkOrderPublishPending(ctx context.Context, tx pgx.Tx, orderID int) error
+	GetUnpublishedOrders(ctx context.Context) ([]*model.Order, error)
+	ClearOrderPublishPending(ctx context.Context, orderID int) error
+	ReleaseStaleTickets(ctx context.Context) (int, error)
+	GetUnpublishedTickets(ctx context.Context) ([]*model.Ticket, error)
+	ClearTicketPublishPending(ctx context.Context, ticketID int) error
+	TryAdvisoryLock(ctx context.Context, key int64) (func(), bool, error)
 }
 
 type OrderPublisher interface {
 	PublishCreatedOrder(ctx context.Context, order *model.Order) error
+	PublishStatusChange(ctx context.Context, order *model.Order, oldStatus model.OrderStatus, changedBy string) error
+	PublishTicket(ctx context.Context, ticket *model.Ticket) error
+}
+
+type BacklogInspector interface {
+	QueueDepth(ctx context.Context) (int, error)
 }
 
 type OrderService struct {
-	repo OrderRepository
-	rmq  OrderPublisher
+	repo      OrderRepository
+	rmq       OrderPublisher
+	backlog   BacklogInspector
+	admission config.AdmissionConfig
+	slots     config.PickupSlotsConfig
+	loyalty   config.LoyaltyConfig
 }
 
-func NewOrderService(r OrderRepository, rmq OrderPublisher) *OrderService {
-	return &OrderService{repo: r, rmq: rmq}
+func NewOrderService(r OrderRepository, rmq OrderPublisher, backlog BacklogInspector, admission config.AdmissionConfig, slots config.PickupSlotsConfig, loyalty config.LoyaltyConfig) *OrderService {
+	return &OrderService{repo: r, rmq: rmq, backlog: backlog, admission: admission, slots: slots, loyalty: loyalty}
 }
 
 func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) (*model.Order, error) {
@@ -58,6 +99,12 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) (*mo
 		return nil, fmt.Errorf("%w: delivery_address is required for delivery orders", model.ValidationError)
 	}
 
+	if err := s.checkAvailability(ctx, order.Items); err != nil {
+		logger.Log(logger.INFO, "order-service", "validation_failed", "order contains unavailable items", rid,
+			map[string]interface{}{"error": err.Error()}, err)
+		return nil, err
+	}
+
 	var total float64
 	for _, item := range order.Items {
 		total += item.Price * float64(item.Quantity)
@@ -73,6 +120,35 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) (*mo
 		order.Priority = 1
 	}
 
+	initialStatus := model.StatusReceived
+	if s.admission.Enabled {
+		load, err := s.kitchenLoad(ctx)
+		if err != nil {
+			logger.Log(logger.ERROR, "order-service", "admission_check_failed", "failed to check kitchen load, admitting order", rid,
+				map[string]interface{}{"error": err.Error()}, err)
+		} else {
+			if load.Overloaded() {
+				logger.Log(logger.INFO, "order-service", "kitchen_overloaded", "kitchen backlog exceeds capacity", rid,
+					map[string]interface{}{
+						"queued_orders":  load.QueuedOrders,
+						"online_workers": load.OnlineWorkers,
+						"staff":          load.Staff,
+						"capacity":       load.Capacity,
+						"mode":           s.admission.Mode,
+					}, nil)
+
+				switch model.AdmissionMode(s.admission.Mode) {
+				case model.AdmissionReject:
+					return nil, model.KitchenOverloadedError
+				case model.AdmissionWaitlist:
+					initialStatus = model.StatusWaitlisted
+				}
+			}
+			eta := s.quoteETA(load)
+			order.EstimatedCompletion = &eta
+		}
+	}
+
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
 		logger.Log(logger.ERROR, "order-service", "db_transaction_failed", "failed to begin transaction", rid,
@@ -98,10 +174,19 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) (*mo
 
 	orderNumber := fmt.Sprintf("ORD_%s_%03d", today, seq)
 	order.Number = orderNumber
-	order.Status = model.StatusReceived
+	order.Status = initialStatus
 	order.CreatedAt = time.Now()
 	order.UpdatedAt = time.Now()
 
+	if order.RedeemPoints > 0 {
+		if err := s.applyRedemption(ctx, tx, order); err != nil {
+			rollback()
+			logger.Log(logger.ERROR, "order-service", "loyalty_redeem_failed", "failed to redeem loyalty points", rid,
+				map[string]interface{}{"order_number": order.Number, "redeem_points": order.RedeemPoints, "error": err.Error()}, err)
+			return nil, err
+		}
+	}
+
 	orderID, err := s.repo.CreateOrder(ctx, tx, order)
 	if err != nil {
 		rollback()
@@ -111,6 +196,44 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) (*mo
 	}
 	order.ID = orderID
 
+	if order.RedeemPoints > 0 {
+		notes := fmt.Sprintf("redeemed for %.2f discount", order.DiscountAmount)
+		_, err := s.repo.CreateLoyaltyEntry(ctx, tx, &model.LoyaltyEntry{
+			LoyaltyID: *order.LoyaltyID,
+			OrderID:   &orderID,
+			Type:      model.LoyaltyRedeem,
+			Points:    -order.RedeemPoints,
+			Notes:     &notes,
+		})
+		if err != nil {
+			rollback()
+			logger.Log(logger.ERROR, "order-service", "db_insert_failed", "failed to insert loyalty entry", rid,
+				map[string]interface{}{"order_number": order.Number, "error": err.Error()}, err)
+			return nil, err
+		}
+	}
+
+	if order.PickupSlotID != nil {
+		itemCount := 0
+		for _, item := range order.Items {
+			itemCount += item.Quantity
+		}
+
+		reserved, err := s.repo.ReserveSlot(ctx, tx, *order.PickupSlotID, itemCount)
+		if err != nil {
+			rollback()
+			logger.Log(logger.ERROR, "order-service", "slot_reservation_failed", "failed to reserve pickup slot", rid,
+				map[string]interface{}{"order_number": order.Number, "pickup_slot_id": *order.PickupSlotID, "error": err.Error()}, err)
+			return nil, err
+		}
+		if !reserved {
+			rollback()
+			logger.Log(logger.INFO, "order-service", "slot_unavailable", "pickup slot is full or closed", rid,
+				map[string]interface{}{"order_number": order.Number, "pickup_slot_id": *order.PickupSlotID}, nil)
+			return nil, model.SlotUnavailableError
+		}
+	}
+
 	for i := range order.Items {
 		order.Items[i].OrderID = orderID
 		order.Items[i].CreatedAt = time.Now()
@@ -124,9 +247,23 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) (*mo
 		order.Items[i].ID = itemID
 	}
 
+	reserved, err := s.repo.ReserveStock(ctx, tx, orderID, order.Items)
+	if err != nil {
+		rollback()
+		logger.Log(logger.ERROR, "order-service", "stock_reservation_failed", "failed to reserve stock", rid,
+			map[string]interface{}{"order_number": order.Number, "error": err.Error()}, err)
+		return nil, err
+	}
+	if !reserved {
+		rollback()
+		logger.Log(logger.INFO, "order-service", "out_of_stock", "not enough stock for order", rid,
+			map[string]interface{}{"order_number": order.Number}, nil)
+		return nil, model.OutOfStockError
+	}
+
 	logEntry := &model.OrderStatusLog{
 		OrderID:   orderID,
-		Status:    model.StatusReceived,
+		Status:    initialStatus,
 		ChangedBy: "system",
 		ChangedAt: time.Now(),
 		Notes:     nil,
@@ -147,6 +284,12 @@ func (s *OrderService) CreateOrder(ctx context.Context, order *model.Order) (*mo
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if order.Status == model.StatusWaitlisted {
+		logger.Log(logger.DEBUG, "order-service", "order_waitlisted", "order placed on waitlist", rid,
+			map[string]interface{}{"order_number": order.Number, "priority": order.Priority}, nil)
+		return order, nil
+	}
+
 	if err := s.rmq.PublishCreatedOrder(ctx, order); err != nil {
 		logger.Log(logger.ERROR, "order-service", "rabbitmq_publish_failed", "failed to publish order", rid,
 			map[string]interface{}{"order_number": order.Number, "priority": order.Priority}, err)
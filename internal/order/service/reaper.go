@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant-system/internal/order/model"
+	"restaurant-system/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const reaperName = "reaper"
+
+// reaperLockKey is the advisory lock that keeps replicas of the service from
+// reaping at the same time and republishing the same orders twice.
+const reaperLockKey = 0x72656170 // "reap"
+
+// ReapStaleWorkers marks workers that stopped sending heartbeats offline and
+// sends the orders and station tickets they were cooking back to the kitchen.
+// Requeued orders and tickets are flagged in the same transaction as their
+// status change and the flag is only cleared once the publish succeeds, so a
+// failed publish is retried on the next tick.
+func (s *OrderService) ReapStaleWorkers(ctx context.Context, staleAfter time.Duration) (int, error) {
+	rid := fmt.Sprintf("reaper-%d", time.Now().UnixNano())
+
+	unlock, locked, err := s.repo.TryAdvisoryLock(ctx, reaperLockKey)
+	if err != nil {
+		return 0, err
+	}
+	if !locked {
+		logger.Log(logger.DEBUG, "order-service", "reaper_skipped", "another replica is reaping", rid, nil, nil)
+		return 0, nil
+	}
+	defer unlock()
+
+	workers, err := s.repo.MarkStaleWorkersOffline(ctx, staleAfter)
+	if err != nil {
+		return 0, err
+	}
+	for _, name := range workers {
+		logger.Log(logger.INFO, "order-service", "worker_reaped", "stale worker marked offline", rid,
+			map[string]interface{}{"worker_name": name, "stale_after": staleAfter.String()}, nil)
+	}
+
+	orders, err := s.repo.GetStuckOrders(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, order := range orders {
+		worker := ""
+		if order.ProcessedBy != nil {
+			worker = *order.ProcessedBy
+		}
+
+		ok, err := s.requeueOrder(ctx, order, worker)
+		if err != nil {
+			logger.Log(logger.ERROR, "order-service", "order_recovery_failed", "failed to requeue stuck order", rid,
+				map[string]interface{}{"order_number": order.Number, "worker_name": worker}, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err := s.rmq.PublishStatusChange(ctx, order, model.StatusCooking, reaperName); err != nil {
+			logger.Log(logger.ERROR, "order-service", "status_publish_failed", "failed to publish status update", rid,
+				map[string]interface{}{"order_number": order.Number}, err)
+		}
+
+		recovered++
+		logger.Log(logger.INFO, "order-service", "order_recovered", "stuck order returned to kitchen", rid,
+			map[string]interface{}{"order_number": order.Number, "worker_name": worker}, nil)
+	}
+
+	released, err := s.repo.ReleaseStaleTickets(ctx)
+	if err != nil {
+		return recovered, err
+	}
+	if released > 0 {
+		recovered += released
+		logger.Log(logger.INFO, "order-service", "tickets_recovered", "station tickets of offline workers returned to their stations", rid,
+			map[string]interface{}{"tickets": released}, nil)
+	}
+
+	if err := s.republishOrders(ctx, rid); err != nil {
+		return recovered, err
+	}
+	if err := s.republishTickets(ctx, rid); err != nil {
+		return recovered, err
+	}
+
+	return recovered, nil
+}
+
+// republishOrders publishes every requeued order that has not reached the
+// kitchen yet.
+func (s *OrderService) republishOrders(ctx context.Context, rid string) error {
+	orders, err := s.repo.GetUnpublishedOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		s.publishPendingOrder(ctx, order, rid, "failed to republish recovered order")
+	}
+	return nil
+}
+
+// publishPendingOrder publishes an order flagged for publishing and clears
+// the flag. A failed publish is only logged; the reaper retries it later.
+func (s *OrderService) publishPendingOrder(ctx context.Context, order *model.Order, rid, failMsg string) {
+	if err := s.rmq.PublishCreatedOrder(ctx, order); err != nil {
+		logger.Log(logger.ERROR, "order-service", "rabbitmq_publish_failed", failMsg, rid,
+			map[string]interface{}{"order_number": order.Number, "priority": order.Priority}, err)
+		return
+	}
+
+	if err := s.repo.ClearOrderPublishPending(ctx, order.ID); err != nil {
+		logger.Log(logger.ERROR, "order-service", "db_update_failed", "failed to clear publish flag", rid,
+			map[string]interface{}{"order_number": order.Number}, err)
+	}
+}
+
+// republishTickets publishes every released station ticket that has not
+// reached its station yet.
+func (s *OrderService) republishTickets(ctx context.Context, rid string) error {
+	tickets, err := s.repo.GetUnpublishedTickets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		if err := s.rmq.PublishTicket(ctx, ticket); err != nil {
+			logger.Log(logger.ERROR, "order-service", "rabbitmq_publish_failed", "failed to republish station ticket", rid,
+				map[string]interface{}{"order_number": ticket.OrderNumber, "ticket_id": ticket.ID, "station": ticket.Station}, err)
+			continue
+		}
+
+		if err := s.repo.ClearTicketPublishPending(ctx, ticket.ID); err != nil {
+			logger.Log(logger.ERROR, "order-service", "db_update_failed", "failed to clear publish flag", rid,
+				map[string]interface{}{"order_number": ticket.OrderNumber, "ticket_id": ticket.ID}, err)
+		}
+	}
+	return nil
+}
+
+func (s *OrderService) requeueOrder(ctx context.Context, order *model.Order, worker string) (bool, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			logger.Log(logger.ERROR, "order-service", "db_rollback_failed", "failed to rollback transaction", "",
+				map[string]interface{}{"error": err.Error()}, err)
+		}
+	}()
+
+	ok, err := s.repo.UpdateOrderStatus(ctx, tx, order.ID, model.StatusCooking, model.StatusReceived)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	notes := fmt.Sprintf("worker %s stopped responding; order returned to the queue", worker)
+	_, err = s.repo.CreateLog(ctx, tx, &model.OrderStatusLog{
+		OrderID:   order.ID,
+		Status:    model.StatusReceived,
+		ChangedBy: reaperName,
+		ChangedAt: time.Now(),
+		Notes:     &notes,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.repo.MarkOrderPublishPending(ctx, tx, order.ID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	order.Status = model.StatusReceived
+	return true, nil
+}
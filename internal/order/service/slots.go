@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant-system/internal/order/model"
+)
+
+func (s *OrderService) GetSlots(ctx context.Context, date time.Time) ([]model.PickupSlot, error) {
+	slots, err := s.daySlots(date)
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return []model.PickupSlot{}, nil
+	}
+
+	if err := s.repo.EnsureSlots(ctx, slots); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSlots(ctx, slots[0].Start, slots[len(slots)-1].End)
+}
+
+func (s *OrderService) daySlots(date time.Time) ([]model.PickupSlot, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	open, err := time.Parse("15:04", s.slots.OpenTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pickup_slots.open_time: %w", err)
+	}
+	closing, err := time.Parse("15:04", s.slots.CloseTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pickup_slots.close_time: %w", err)
+	}
+	if s.slots.DurationMinutes <= 0 {
+		return nil, fmt.Errorf("invalid pickup_slots.duration_minutes: %d", s.slots.DurationMinutes)
+	}
+
+	duration := time.Duration(s.slots.DurationMinutes) * time.Minute
+	start := day.Add(time.Duration(open.Hour())*time.Hour + time.Duration(open.Minute())*time.Minute)
+	end := day.Add(time.Duration(closing.Hour())*time.Hour + time.Duration(closing.Minute())*time.Minute)
+
+	var slots []model.PickupSlot
+	for t := start; !t.Add(duration).After(end); t = t.Add(duration) {
+		slots = append(slots, model.PickupSlot{
+			Start:     t,
+			End:       t.Add(duration),
+			MaxOrders: s.slots.MaxOrders,
+			MaxItems:  s.slots.MaxItems,
+		})
+	}
+
+	return slots, nil
+}
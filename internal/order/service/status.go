@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"restaurant-system/internal/order/model"
+	"restaurant-system/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *OrderService) ChangeStatus(ctx context.Context, orderNumber string, to model.OrderStatus, req *model.ChangeStatusRequest) (*model.Order, error) {
+	rid := ""
+	if v := ctx.Value("request_id"); v != nil {
+		if str, ok := v.(string); ok {
+			rid = str
+		}
+	}
+
+	if strings.TrimSpace(req.ChangedBy) == "" {
+		return nil, fmt.Errorf("%w: changed_by is required", model.ValidationError)
+	}
+
+	order, err := s.repo.GetOrder(ctx, orderNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.OrderNotFoundError
+		}
+		return nil, err
+	}
+
+	from := order.Status
+	if !model.CanTransitionManually(from, to) {
+		return nil, fmt.Errorf("%w: %s -> %s", model.OrderStateError, from, to)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			logger.Log(logger.ERROR, "order-service", "db_rollback_failed", "failed to rollback transaction", rid,
+				map[string]interface{}{"error": err.Error()}, err)
+		}
+	}()
+
+	updated, err := s.repo.UpdateOrderStatus(ctx, tx, order.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: order changed concurrently", model.OrderStateError)
+	}
+	order.Status = to
+
+	var notes *string
+	if req.Reason != "" {
+		notes = &req.Reason
+	}
+	_, err = s.repo.CreateLog(ctx, tx, &model.OrderStatusLog{
+		OrderID:   order.ID,
+		Status:    to,
+		ChangedBy: req.ChangedBy,
+		ChangedAt: time.Now(),
+		Notes:     notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch to {
+	case model.StatusCompleted:
+		err = s.awardPoints(ctx, tx, order)
+	case model.StatusCancelled, model.StatusRefunded:
+		err = s.reversePoints(ctx, tx, order)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if to == model.StatusCancelled {
+		if err := s.repo.ReleaseStock(ctx, tx, order.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if to == model.StatusCancelled && order.PickupSlotID != nil {
+		itemCount := 0
+		for _, item := range order.Items {
+			itemCount += item.Quantity
+		}
+		if err := s.repo.ReleaseSlot(ctx, tx, *order.PickupSlotID, itemCount); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Log(logger.INFO, "order-service", "order_status_changed", "order status changed", rid,
+		map[string]interface{}{
+			"order_number": order.Number,
+			"old_status":   from,
+			"new_status":   to,
+			"changed_by":   req.ChangedBy,
+		}, nil)
+
+	if err := s.rmq.PublishStatusChange(ctx, order, from, req.ChangedBy); err != nil {
+		logger.Log(logger.ERROR, "order-service", "status_publish_failed", "failed to publish status update", rid,
+			map[string]interface{}{"order_number": order.Number}, err)
+	}
+
+	return order, nil
+}
@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
+	"restaurant-system/internal/tracking/infrastructure/rmq"
 	"restaurant-system/internal/tracking/service"
 	"restaurant-system/pkg/logger"
+	"restaurant-system/pkg/websocket"
 )
 
 type TrackingHandler struct {
@@ -79,3 +85,545 @@ func (h *TrackingHandler) GetWorkersStatus(w http.ResponseWriter, r *http.Reques
 		return
 	}
 }
+
+func (h *TrackingHandler) GetFeedbackSummary(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "feedback summary request received", rid,
+		map[string]interface{}{"endpoint": "feedback/summary"}, nil)
+
+	summary, err := h.service.GetFeedbackSummary(r.Context())
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "feedback_summary_failed", "failed to get feedback summary", rid, nil, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(summary)
+	if err != nil {
+		return
+	}
+}
+
+func (h *TrackingHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "dead letters request received", rid,
+		map[string]interface{}{"endpoint": "admin/dead-letters"}, nil)
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	letters, err := h.service.GetDeadLetters(r.Context(), limit)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "dead_letters_failed", "failed to list dead letters", rid, nil, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(letters)
+	if err != nil {
+		return
+	}
+}
+
+func (h *TrackingHandler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	orderNumber := r.URL.Query().Get("order_number")
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "dead letter replay request received", rid,
+		map[string]interface{}{"endpoint": "admin/dead-letters/replay", "order_number": orderNumber}, nil)
+
+	result, err := h.service.ReplayDeadLetters(r.Context(), orderNumber)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "dead_letter_replay_failed", "failed to replay dead letters", rid,
+			map[string]interface{}{"order_number": orderNumber}, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		return
+	}
+}
+
+func (h *TrackingHandler) SendWorkerCommand(w http.ResponseWriter, r *http.Request, workerName, command string) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "worker command request received", rid,
+		map[string]interface{}{"worker_name": workerName, "command": command}, nil)
+
+	var req struct {
+		IssuedBy string `json:"issued_by"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.IssuedBy == "" {
+		req.IssuedBy = "admin"
+	}
+
+	result, err := h.service.SendWorkerCommand(r.Context(), workerName, command, req.IssuedBy)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "worker_command_failed", "failed to send worker command", rid,
+			map[string]interface{}{"worker_name": workerName, "command": command}, err)
+
+		switch {
+		case errors.Is(err, service.UnknownCommandError):
+			http.Error(w, "Not found", http.StatusNotFound)
+		case errors.Is(err, service.WorkerNotFoundError):
+			http.Error(w, "Worker not found", http.StatusNotFound)
+		case errors.Is(err, service.WorkerOfflineError):
+			http.Error(w, "Worker is offline", http.StatusConflict)
+		default:
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		return
+	}
+}
+
+func (h *TrackingHandler) GetStageAnalytics(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "stage analytics request received", rid,
+		map[string]interface{}{"endpoint": "analytics/stages"}, nil)
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = "worker"
+	}
+
+	hours := 24
+	if v := r.URL.Query().Get("hours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "hours must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		hours = n
+	}
+
+	analytics, err := h.service.GetStageAnalytics(r.Context(), groupBy, time.Duration(hours)*time.Hour)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "stage_analytics_failed", "failed to get stage analytics", rid,
+			map[string]interface{}{"group_by": groupBy}, err)
+		if errors.Is(err, service.InvalidGroupError) {
+			http.Error(w, "group_by must be worker, order_type or hour", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(analytics)
+	if err != nil {
+		return
+	}
+}
+
+func (h *TrackingHandler) GetShifts(w http.ResponseWriter, r *http.Request, workerName string) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "worker shifts request received", rid,
+		map[string]interface{}{"worker_name": workerName}, nil)
+
+	shifts, err := h.service.GetShifts(r.Context(), workerName)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "worker_shifts_failed", "failed to get worker shifts", rid,
+			map[string]interface{}{"worker_name": workerName}, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(shifts)
+	if err != nil {
+		return
+	}
+}
+
+func (h *TrackingHandler) CreateShift(w http.ResponseWriter, r *http.Request, workerName string) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "create shift request received", rid,
+		map[string]interface{}{"worker_name": workerName}, nil)
+
+	var req service.ShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	shift, err := h.service.CreateShift(r.Context(), workerName, &req)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "create_shift_failed", "failed to create worker shift", rid,
+			map[string]interface{}{"worker_name": workerName}, err)
+		if errors.Is(err, service.InvalidShiftError) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(shift)
+	if err != nil {
+		return
+	}
+}
+
+func (h *TrackingHandler) DeleteShift(w http.ResponseWriter, r *http.Request, workerName, shiftID string) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	id, err := strconv.Atoi(shiftID)
+	if err != nil {
+		http.Error(w, "Shift not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.service.DeleteShift(r.Context(), workerName, id); err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "delete_shift_failed", "failed to delete worker shift", rid,
+			map[string]interface{}{"worker_name": workerName, "shift_id": id}, err)
+		if errors.Is(err, service.ShiftNotFoundError) {
+			http.Error(w, "Shift not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TrackingHandler) GetStaffing(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "staffing request received", rid,
+		map[string]interface{}{"endpoint": "workers/staffing"}, nil)
+
+	staffing, err := h.service.GetStaffing(r.Context())
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "staffing_failed", "failed to get staffing", rid, nil, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(staffing)
+	if err != nil {
+		return
+	}
+}
+
+// StreamOrderEvents streams the order's status changes as Server-Sent Events.
+// A new stream starts with a snapshot of the current status. A reconnecting
+// client sends Last-Event-ID and gets every change it missed instead.
+func (h *TrackingHandler) StreamOrderEvents(w http.ResponseWriter, r *http.Request, orderNumber string) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "order events stream requested", rid,
+		map[string]interface{}{"order_number": orderNumber, "endpoint": "events"}, nil)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	resumeFrom := 0
+	if lastEventID != "" {
+		n, err := strconv.Atoi(lastEventID)
+		if err != nil || n < 0 {
+			http.Error(w, "Last-Event-ID must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		resumeFrom = n
+	}
+
+	// Subscribe before reading the log so no change falls between the two.
+	wake, unsubscribe := h.service.SubscribeOrder(orderNumber)
+	defer unsubscribe()
+
+	snapshot, lastID, err := h.service.GetOrderSnapshot(r.Context(), orderNumber)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "order_events_failed", "failed to get order snapshot", rid,
+			map[string]interface{}{"order_number": orderNumber}, err)
+		if errors.Is(err, service.OrderNotFoundError) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sendEvents := func() error {
+		events, err := h.service.GetOrderEvents(r.Context(), orderNumber, lastID)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := writeEvent(w, event.ID, "status", event); err != nil {
+				return err
+			}
+			lastID = event.ID
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	if lastEventID == "" {
+		if err := writeEvent(w, lastID, "snapshot", snapshot); err != nil {
+			return
+		}
+		flusher.Flush()
+	} else {
+		lastID = resumeFrom
+		if err := sendEvents(); err != nil {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case _, ok := <-wake:
+			if !ok {
+				return
+			}
+			if err := sendEvents(); err != nil {
+				if r.Context().Err() == nil {
+					logger.Log(logger.ERROR, "tracking-service", "order_events_failed", "failed to send order events", rid,
+						map[string]interface{}{"order_number": orderNumber}, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, id int, event string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, body)
+	return err
+}
+
+// KitchenDashboard feeds the kitchen wall screen over a WebSocket. It sends a
+// snapshot on connect and then one message per change: order status changes,
+// new queue counts when orders enter or leave the queue, and workers going
+// online or offline. A full snapshot is resent every minute in case the
+// screen missed something.
+func (h *TrackingHandler) KitchenDashboard(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		logger.Log(logger.DEBUG, "tracking-service", "websocket_upgrade_failed", "failed to upgrade dashboard connection", rid, nil, err)
+		return
+	}
+	defer conn.Close()
+
+	logger.Log(logger.DEBUG, "tracking-service", "dashboard_connected", "kitchen dashboard connected", rid,
+		map[string]interface{}{"remote_addr": r.RemoteAddr}, nil)
+
+	// The request context ends with the hijack, so the reader signals when
+	// the screen goes away.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	updates, unsubscribe := h.service.SubscribeDashboard()
+	defer unsubscribe()
+
+	ctx := context.Background()
+	send := func(msg interface{}) error {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return conn.WriteText(body)
+	}
+
+	snapshot, err := h.service.GetDashboardSnapshot(ctx)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "dashboard_snapshot_failed", "failed to build dashboard snapshot", rid, nil, err)
+		return
+	}
+	if err := send(snapshot); err != nil {
+		return
+	}
+
+	workers, err := h.service.GetWorkerStates(ctx)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "dashboard_workers_failed", "failed to get worker states", rid, nil, err)
+		return
+	}
+
+	workerPoll := time.NewTicker(5 * time.Second)
+	defer workerPoll.Stop()
+	refresh := time.NewTicker(time.Minute)
+	defer refresh.Stop()
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case <-gone:
+			logger.Log(logger.DEBUG, "tracking-service", "dashboard_disconnected", "kitchen dashboard disconnected", rid, nil, nil)
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			err = h.sendOrderUpdate(ctx, send, update)
+		case <-workerPoll.C:
+			var current map[string]string
+			current, err = h.service.GetWorkerStates(ctx)
+			if err == nil {
+				err = sendWorkerChanges(send, workers, current)
+				workers = current
+			}
+		case <-refresh.C:
+			snapshot, err = h.service.GetDashboardSnapshot(ctx)
+			if err == nil {
+				err = send(snapshot)
+			}
+		case <-ping.C:
+			err = conn.Ping()
+		}
+		if err != nil {
+			logger.Log(logger.DEBUG, "tracking-service", "dashboard_closed", "kitchen dashboard feed stopped", rid, nil, err)
+			return
+		}
+	}
+}
+
+func (h *TrackingHandler) sendOrderUpdate(ctx context.Context, send func(interface{}) error, update *rmq.StatusUpdate) error {
+	msg := map[string]interface{}{
+		"type":         "order_status",
+		"order_number": update.OrderNumber,
+		"old_status":   update.OldStatus,
+		"new_status":   update.NewStatus,
+		"changed_by":   update.ChangedBy,
+		"timestamp":    update.Timestamp.Format(time.RFC3339),
+	}
+	if update.NewStatus == "cooking" {
+		msg["worker_name"] = update.ChangedBy
+		msg["started_at"] = update.Timestamp.Format(time.RFC3339)
+		if !update.EstimatedCompletion.IsZero() {
+			msg["estimated_completion"] = update.EstimatedCompletion.Format(time.RFC3339)
+		}
+	}
+	if err := send(msg); err != nil {
+		return err
+	}
+
+	if update.OldStatus != "received" && update.NewStatus != "received" {
+		return nil
+	}
+
+	queued, err := h.service.GetQueuedOrders(ctx)
+	if err != nil {
+		return err
+	}
+	return send(map[string]interface{}{"type": "queued", "queued": queued})
+}
+
+func sendWorkerChanges(send func(interface{}) error, previous, current map[string]string) error {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		status := current[name]
+		if old, ok := previous[name]; ok && old == status {
+			continue
+		}
+		msg := map[string]interface{}{
+			"type":        "worker",
+			"worker_name": name,
+			"status":      status,
+		}
+		if old, ok := previous[name]; ok {
+			msg["previous_status"] = old
+		}
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *TrackingHandler) GetKitchenBacklog(w http.ResponseWriter, r *http.Request) {
+	rid := fmt.Sprintf("req-%d", time.Now().UnixNano())
+
+	logger.Log(logger.DEBUG, "tracking-service", "request_received", "kitchen backlog request received", rid,
+		map[string]interface{}{"endpoint": "kitchen/backlog"}, nil)
+
+	backlog, err := h.service.GetKitchenBacklog(r.Context())
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "kitchen_backlog_failed", "failed to get kitchen backlog", rid, nil, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(backlog)
+	if err != nil {
+		return
+	}
+}
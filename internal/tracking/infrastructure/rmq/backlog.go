@@ -0,0 +1,57 @@
+package rmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"restaurant-system/pkg/rabbitmq"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type QueueStats struct {
+	Name      string `json:"name"`
+	Exists    bool   `json:"exists"`
+	Messages  int    `json:"messages"`
+	Consumers int    `json:"consumers"`
+}
+
+// QueueInspector reads queue depths with passive declares, which never
+// create a queue or touch its messages.
+type QueueInspector struct {
+	rabbitmq *rabbitmq.RabbitMQ
+	queues   []string
+}
+
+func NewQueueInspector(rabbitmq *rabbitmq.RabbitMQ, queues []string) *QueueInspector {
+	return &QueueInspector{rabbitmq: rabbitmq, queues: queues}
+}
+
+func (i *QueueInspector) Inspect(ctx context.Context) ([]QueueStats, error) {
+	stats := make([]QueueStats, 0, len(i.queues))
+	for _, name := range i.queues {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		queue, err := i.rabbitmq.InspectQueue(name)
+		if err != nil {
+			var amqpErr *amqp091.Error
+			if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.NotFound {
+				stats = append(stats, QueueStats{Name: name})
+				continue
+			}
+			return nil, fmt.Errorf("failed to inspect queue %s: %w", name, err)
+		}
+
+		stats = append(stats, QueueStats{
+			Name:      queue.Name,
+			Exists:    true,
+			Messages:  queue.Messages,
+			Consumers: queue.Consumers,
+		})
+	}
+
+	return stats, nil
+}
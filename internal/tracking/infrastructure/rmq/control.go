@@ -0,0 +1,95 @@
+package rmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"restaurant-system/pkg/rabbitmq"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+const controlExchange = "worker_control"
+
+type controlMessage struct {
+	Command   string    `json:"command"`
+	IssuedBy  string    `json:"issued_by"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type ControlPublisher struct {
+	rabbitmq *rabbitmq.RabbitMQ
+}
+
+func NewControlPublisher(rabbitmq *rabbitmq.RabbitMQ) (*ControlPublisher, error) {
+	err := rabbitmq.Channel().ExchangeDeclare(
+		controlExchange,
+		"direct",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	return &ControlPublisher{rabbitmq: rabbitmq}, nil
+}
+
+// SendCommand publishes a command to the worker's control queue and reports
+// whether the queue took it. The queue only exists while the worker process
+// runs, so the message is published as mandatory on a confirming channel of
+// its own: the broker returns it, before confirming, when nothing is bound.
+func (p *ControlPublisher) SendCommand(ctx context.Context, workerName, command, issuedBy string) (bool, error) {
+	body, err := json.Marshal(controlMessage{
+		Command:   command,
+		IssuedBy:  issuedBy,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal control message: %w", err)
+	}
+
+	ch, err := p.rabbitmq.OpenChannel()
+	if err != nil {
+		return false, err
+	}
+	defer ch.Close()
+
+	if err := ch.Confirm(false); err != nil {
+		return false, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	returns := ch.NotifyReturn(make(chan amqp091.Return, 1))
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx,
+		controlExchange,
+		workerName,
+		true,
+		false,
+		amqp091.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+		})
+	if err != nil {
+		return false, fmt.Errorf("failed to publish control message: %w", err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm control message: %w", err)
+	}
+	if !acked {
+		return false, fmt.Errorf("control message was rejected by the broker")
+	}
+
+	select {
+	case <-returns:
+		return false, nil
+	default:
+		return true, nil
+	}
+}
@@ -0,0 +1,169 @@
+package rmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"restaurant-system/pkg/rabbitmq"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// These mirror the dead-letter topology declared by the kitchen workers.
+const (
+	DeadLetterQueueName = "kitchen_dead_letter"
+	attemptsHeader      = "x-attempts"
+	lastErrorHeader     = "x-last-error"
+	originalQueueHeader = "x-original-queue"
+)
+
+type DeadLetter struct {
+	OrderNumber    string          `json:"order_number"`
+	Queue          string          `json:"queue"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"last_error"`
+	DeadLetteredAt *time.Time      `json:"dead_lettered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+type DeadLetterQueue struct {
+	rabbitmq *rabbitmq.RabbitMQ
+}
+
+func NewDeadLetterQueue(rabbitmq *rabbitmq.RabbitMQ) *DeadLetterQueue {
+	return &DeadLetterQueue{rabbitmq: rabbitmq}
+}
+
+// List peeks at up to limit dead-lettered messages. Nothing is acked, so
+// closing the channel puts every message back on the queue.
+func (q *DeadLetterQueue) List(ctx context.Context, limit int) ([]DeadLetter, error) {
+	ch, err := q.open()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	letters := []DeadLetter{}
+	for len(letters) < limit {
+		msg, ok, err := ch.Get(DeadLetterQueueName, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+		letters = append(letters, toDeadLetter(msg))
+	}
+
+	return letters, nil
+}
+
+// Replay sends dead-lettered messages back to the queue they failed on with
+// a fresh attempt count. An empty orderNumber replays everything; messages
+// that don't match stay unacked and return to the queue when the channel
+// closes.
+func (q *DeadLetterQueue) Replay(ctx context.Context, orderNumber string) ([]DeadLetter, error) {
+	ch, err := q.open()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	replayed := []DeadLetter{}
+	for {
+		msg, ok, err := ch.Get(DeadLetterQueueName, false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		letter := toDeadLetter(msg)
+		if letter.Queue == "" || (orderNumber != "" && letter.OrderNumber != orderNumber) {
+			continue
+		}
+
+		err = ch.PublishWithContext(ctx,
+			"",
+			letter.Queue,
+			false,
+			false,
+			amqp091.Publishing{
+				ContentType:  "application/json",
+				Body:         msg.Body,
+				DeliveryMode: amqp091.Persistent,
+				Priority:     msg.Priority,
+				Timestamp:    time.Now(),
+			})
+		if err != nil {
+			return replayed, fmt.Errorf("failed to replay message: %w", err)
+		}
+
+		if err := msg.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to ack replayed message: %w", err)
+		}
+
+		replayed = append(replayed, letter)
+	}
+
+	return replayed, nil
+}
+
+func (q *DeadLetterQueue) open() (*amqp091.Channel, error) {
+	ch, err := q.rabbitmq.OpenChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ch.QueueDeclare(
+		DeadLetterQueueName,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+
+	return ch, nil
+}
+
+func toDeadLetter(msg amqp091.Delivery) DeadLetter {
+	var payload struct {
+		OrderNumber string `json:"order_number"`
+	}
+	json.Unmarshal(msg.Body, &payload)
+
+	letter := DeadLetter{
+		OrderNumber: payload.OrderNumber,
+		Payload:     json.RawMessage(msg.Body),
+	}
+	if !json.Valid(msg.Body) {
+		letter.Payload = nil
+	}
+
+	if queue, ok := msg.Headers[originalQueueHeader].(string); ok {
+		letter.Queue = queue
+	}
+	if lastError, ok := msg.Headers[lastErrorHeader].(string); ok {
+		letter.LastError = lastError
+	}
+	switch v := msg.Headers[attemptsHeader].(type) {
+	case int32:
+		letter.Attempts = int(v)
+	case int64:
+		letter.Attempts = int(v)
+	}
+	if !msg.Timestamp.IsZero() {
+		ts := msg.Timestamp
+		letter.DeadLetteredAt = &ts
+	}
+
+	return letter
+}
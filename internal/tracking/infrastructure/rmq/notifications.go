@@ -0,0 +1,118 @@
+package rmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"restaurant-system/pkg/rabbitmq"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type StatusUpdate struct {
+	OrderNumber         string    `json:"order_number"`
+	OldStatus           string    `json:"old_status"`
+	NewStatus           string    `json:"new_status"`
+	ChangedBy           string    `json:"changed_by"`
+	Timestamp           time.Time `json:"timestamp"`
+	EstimatedCompletion time.Time `json:"estimated_completion"`
+}
+
+// StatusConsumer receives every status change published to
+// notifications_fanout through a private queue that disappears with the
+// service. Messages are auto-acked: a missed update is recovered from
+// order_status_log.
+type StatusConsumer struct {
+	channel *amqp091.Channel
+	queue   amqp091.Queue
+}
+
+func NewStatusConsumer(rabbitmq *rabbitmq.RabbitMQ) (*StatusConsumer, error) {
+	ch, err := rabbitmq.OpenChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	err = ch.ExchangeDeclare(
+		"notifications_fanout",
+		"fanout",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	queue, err := ch.QueueDeclare(
+		"",
+		false,
+		true,
+		true,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	err = ch.QueueBind(
+		queue.Name,
+		"",
+		"notifications_fanout",
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind queue: %w", err)
+	}
+
+	return &StatusConsumer{channel: ch, queue: queue}, nil
+}
+
+func (c *StatusConsumer) Consume(ctx context.Context) (<-chan *StatusUpdate, error) {
+	msgs, err := c.channel.Consume(
+		c.queue.Name,
+		"",
+		true,
+		true,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume messages: %w", err)
+	}
+
+	updates := make(chan *StatusUpdate, 100)
+
+	go func() {
+		defer close(updates)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+
+				var update StatusUpdate
+				if err := json.Unmarshal(msg.Body, &update); err != nil {
+					continue
+				}
+				updates <- &update
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+func (c *StatusConsumer) Close() error {
+	return c.channel.Close()
+}
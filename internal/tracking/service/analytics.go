@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"restaurant-system/pkg/logger"
+)
+
+var InvalidGroupError = errors.New("invalid group_by")
+
+// stageGroups are the dimensions stage timings can be aggregated by. Hours
+// are hours of the day in UTC, taken from the end of the stage.
+var stageGroups = map[string]string{
+	"worker":     `COALESCE(worker_name, 'unknown')`,
+	"order_type": `order_type`,
+	"hour":       `LPAD(EXTRACT(HOUR FROM ended_at AT TIME ZONE 'UTC')::text, 2, '0')`,
+}
+
+func (s *TrackingService) GetStageAnalytics(ctx context.Context, groupBy string, since time.Duration) (map[string]interface{}, error) {
+	groupExpr, ok := stageGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", InvalidGroupError, groupBy)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s AS grp, stage, COUNT(*), AVG(duration_seconds),
+			   percentile_cont(0.5) WITHIN GROUP (ORDER BY duration_seconds),
+			   percentile_cont(0.9) WITHIN GROUP (ORDER BY duration_seconds),
+			   percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_seconds),
+			   MAX(duration_seconds)
+		FROM order_stage_timings
+		WHERE ended_at >= NOW() - make_interval(secs => $1)
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, groupExpr)
+
+	rows, err := s.db.Query(ctx, query, since.Seconds())
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query stage timings", "",
+			map[string]interface{}{"group_by": groupBy}, err)
+		return nil, fmt.Errorf("failed to get stage analytics")
+	}
+	defer rows.Close()
+
+	groups := make([]map[string]interface{}, 0)
+	byGroup := make(map[string]map[string]interface{})
+	for rows.Next() {
+		var group, stage string
+		var count int
+		var avg, p50, p90, p95, maxSeconds float64
+
+		if err := rows.Scan(&group, &stage, &count, &avg, &p50, &p90, &p95, &maxSeconds); err != nil {
+			return nil, err
+		}
+
+		stages, ok := byGroup[group]
+		if !ok {
+			stages = make(map[string]interface{})
+			byGroup[group] = stages
+			groups = append(groups, map[string]interface{}{
+				groupBy:  group,
+				"stages": stages,
+			})
+		}
+
+		stages[stage] = map[string]interface{}{
+			"count":       count,
+			"avg_seconds": roundSeconds(avg),
+			"p50_seconds": roundSeconds(p50),
+			"p90_seconds": roundSeconds(p90),
+			"p95_seconds": roundSeconds(p95),
+			"max_seconds": roundSeconds(maxSeconds),
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"group_by":    groupBy,
+		"since_hours": since.Hours(),
+		"groups":      groups,
+	}, nil
+}
+
+func roundSeconds(seconds float64) float64 {
+	return float64(int64(seconds*10+0.5)) / 10
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"restaurant-system/internal/tracking/infrastructure/rmq"
+	"restaurant-system/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type QueueInspector interface {
+	Inspect(ctx context.Context) ([]rmq.QueueStats, error)
+}
+
+// GetKitchenBacklog shows how much work is waiting: the kitchen queues as the
+// broker sees them, and the received and cooking orders as the database sees
+// them. The two can differ while messages are prefetched by workers or
+// orders are being republished.
+func (s *TrackingService) GetKitchenBacklog(ctx context.Context) (map[string]interface{}, error) {
+	queues, err := s.queues.Inspect(ctx)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "queue_inspect_failed", "failed to inspect kitchen queues", "", nil, err)
+		return nil, fmt.Errorf("failed to get kitchen backlog")
+	}
+
+	// Dead letters wait for an operator, not for a worker, so they are
+	// totalled on their own.
+	queuedMessages, consumers, deadLetters := 0, 0, 0
+	for _, q := range queues {
+		if q.Name == rmq.DeadLetterQueueName {
+			deadLetters += q.Messages
+			continue
+		}
+		queuedMessages += q.Messages
+		consumers += q.Consumers
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT status, type, priority, COUNT(*)
+		FROM orders
+		WHERE status IN ('received', 'cooking')
+		GROUP BY status, type, priority
+		ORDER BY status DESC, priority DESC, type
+	`)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query order backlog", "", nil, err)
+		return nil, fmt.Errorf("failed to get kitchen backlog")
+	}
+	defer rows.Close()
+
+	orders := make([]map[string]interface{}, 0)
+	totals := map[string]int{"received": 0, "cooking": 0}
+	for rows.Next() {
+		var status, orderType string
+		var priority, count int
+		if err := rows.Scan(&status, &orderType, &priority, &count); err != nil {
+			return nil, err
+		}
+		orders = append(orders, map[string]interface{}{
+			"status":     status,
+			"order_type": orderType,
+			"priority":   priority,
+			"count":      count,
+		})
+		totals[status] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"queues": queues,
+		"orders": orders,
+		"totals": map[string]interface{}{
+			"queued_messages": queuedMessages,
+			"consumers":       consumers,
+			"dead_letters":    deadLetters,
+			"received":        totals["received"],
+			"cooking":         totals["cooking"],
+		},
+		"oldest_waiting": nil,
+	}
+
+	var number string
+	var createdAt time.Time
+	var ageSeconds float64
+	err = s.db.QueryRow(ctx, `
+		SELECT number, created_at, EXTRACT(EPOCH FROM NOW() - created_at)::float8
+		FROM orders
+		WHERE status = 'received'
+		ORDER BY created_at
+		LIMIT 1
+	`).Scan(&number, &createdAt, &ageSeconds)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query oldest waiting order", "", nil, err)
+		return nil, fmt.Errorf("failed to get kitchen backlog")
+	}
+	if err == nil {
+		result["oldest_waiting"] = map[string]interface{}{
+			"order_number": number,
+			"created_at":   createdAt.Format(time.RFC3339),
+			"age_seconds":  int(ageSeconds),
+		}
+	}
+
+	return result, nil
+}
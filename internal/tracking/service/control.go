@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"restaurant-system/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	WorkerNotFoundError = errors.New("worker not found")
+	WorkerOfflineError  = errors.New("worker is offline")
+	UnknownCommandError = errors.New("unknown command")
+)
+
+// workerCommands maps the accepted commands to what is sent to the worker.
+// "drain" is the older name of "drain-and-exit".
+var workerCommands = map[string]string{
+	"pause":          "pause",
+	"resume":         "resume",
+	"drain-and-exit": "drain-and-exit",
+	"drain":          "drain-and-exit",
+}
+
+type ControlPublisher interface {
+	SendCommand(ctx context.Context, workerName, command, issuedBy string) (bool, error)
+}
+
+// SendWorkerCommand forwards a control command to a running worker. The
+// worker applies it and records its new state in the workers table.
+func (s *TrackingService) SendWorkerCommand(ctx context.Context, workerName, command, issuedBy string) (map[string]interface{}, error) {
+	sent, ok := workerCommands[command]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", UnknownCommandError, command)
+	}
+
+	var status string
+	var alive bool
+	err := s.db.QueryRow(ctx, `
+		SELECT status, NOW() - last_seen <= INTERVAL '60 seconds'
+		FROM workers WHERE name = $1
+	`, workerName).Scan(&status, &alive)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, WorkerNotFoundError
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worker: %w", err)
+	}
+	if status == "offline" || !alive {
+		return nil, WorkerOfflineError
+	}
+
+	// A heartbeat can outlive the process by a few seconds; the control
+	// queue cannot, so a returned message means the worker is gone.
+	delivered, err := s.control.SendCommand(ctx, workerName, sent, issuedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !delivered {
+		return nil, fmt.Errorf("%w: no control queue", WorkerOfflineError)
+	}
+
+	logger.Log(logger.INFO, "tracking-service", "worker_command_sent", "control command sent to worker", "",
+		map[string]interface{}{"worker_name": workerName, "command": sent, "issued_by": issuedBy}, nil)
+
+	return map[string]interface{}{
+		"worker_name": workerName,
+		"command":     sent,
+		"status":      status,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"restaurant-system/internal/tracking/infrastructure/rmq"
+)
+
+// dashboardHub hands every status change to the connected kitchen
+// dashboards. A dashboard that falls behind loses updates rather than
+// holding up the others; it catches up on its next full refresh.
+type dashboardHub struct {
+	mu     sync.Mutex
+	subs   map[chan *rmq.StatusUpdate]struct{}
+	closed bool
+}
+
+func newDashboardHub() *dashboardHub {
+	return &dashboardHub{subs: make(map[chan *rmq.StatusUpdate]struct{})}
+}
+
+func (h *dashboardHub) subscribe() (<-chan *rmq.StatusUpdate, func()) {
+	ch := make(chan *rmq.StatusUpdate, 64)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs, ch)
+	}
+}
+
+func (h *dashboardHub) publish(update *rmq.StatusUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+}
+
+func (h *dashboardHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for ch := range h.subs {
+		close(ch)
+		delete(h.subs, ch)
+	}
+}
+
+// SubscribeDashboard returns a channel of all status changes, closed when the
+// service stops.
+func (s *TrackingService) SubscribeDashboard() (<-chan *rmq.StatusUpdate, func()) {
+	return s.dashboard.subscribe()
+}
+
+// GetDashboardSnapshot returns everything the kitchen wall screen shows.
+func (s *TrackingService) GetDashboardSnapshot(ctx context.Context) (map[string]interface{}, error) {
+	queued, err := s.GetQueuedOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cooking, err := s.GetCookingOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workers, err := s.GetWorkerStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(workers))
+	for name := range workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	workerList := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		workerList = append(workerList, map[string]interface{}{"worker_name": name, "status": workers[name]})
+	}
+
+	return map[string]interface{}{
+		"type":    "snapshot",
+		"at":      time.Now().UTC().Format(time.RFC3339),
+		"queued":  queued,
+		"cooking": cooking,
+		"workers": workerList,
+	}, nil
+}
+
+// GetQueuedOrders counts the orders waiting for a worker by type and
+// priority.
+func (s *TrackingService) GetQueuedOrders(ctx context.Context) ([]map[string]interface{}, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT type, priority, COUNT(*), MIN(created_at)
+		FROM orders
+		WHERE status = 'received'
+		GROUP BY type, priority
+		ORDER BY priority DESC, type
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queued orders: %w", err)
+	}
+	defer rows.Close()
+
+	queued := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var orderType string
+		var priority, count int
+		var oldest time.Time
+		if err := rows.Scan(&orderType, &priority, &count, &oldest); err != nil {
+			return nil, err
+		}
+		queued = append(queued, map[string]interface{}{
+			"order_type":    orderType,
+			"priority":      priority,
+			"count":         count,
+			"oldest_wait_s": int(time.Since(oldest).Seconds()),
+		})
+	}
+
+	return queued, rows.Err()
+}
+
+// GetCookingOrders lists the orders on the stove with who is cooking them and
+// for how long, measured from the latest move to cooking.
+func (s *TrackingService) GetCookingOrders(ctx context.Context) ([]map[string]interface{}, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT o.number, o.type, o.priority, COALESCE(o.processed_by, ''), COALESCE(l.changed_at, o.updated_at)
+		FROM orders o
+		LEFT JOIN LATERAL (
+			SELECT changed_at FROM order_status_log
+			WHERE order_id = o.id AND status = 'cooking'
+			ORDER BY changed_at DESC
+			LIMIT 1
+		) l ON true
+		WHERE o.status = 'cooking'
+		ORDER BY 5
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cooking orders: %w", err)
+	}
+	defer rows.Close()
+
+	cooking := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var number, orderType, worker string
+		var priority int
+		var startedAt time.Time
+		if err := rows.Scan(&number, &orderType, &priority, &worker, &startedAt); err != nil {
+			return nil, err
+		}
+		cooking = append(cooking, map[string]interface{}{
+			"order_number":    number,
+			"order_type":      orderType,
+			"priority":        priority,
+			"worker_name":     worker,
+			"started_at":      startedAt.Format(time.RFC3339),
+			"elapsed_seconds": int(time.Since(startedAt).Seconds()),
+		})
+	}
+
+	return cooking, rows.Err()
+}
+
+// GetWorkerStates maps each worker to its status, counting a worker whose
+// heartbeat has stopped as offline.
+func (s *TrackingService) GetWorkerStates(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT name,
+			   CASE
+				   WHEN NOW() - last_seen > INTERVAL '60 seconds' THEN 'offline'
+				   ELSE status
+			   END
+		FROM workers
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query worker states: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[string]string)
+	for rows.Next() {
+		var name, status string
+		if err := rows.Scan(&name, &status); err != nil {
+			return nil, err
+		}
+		states[name] = status
+	}
+
+	return states, rows.Err()
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"restaurant-system/pkg/logger"
+)
+
+const maxDeadLetterPage = 500
+
+func (s *TrackingService) GetDeadLetters(ctx context.Context, limit int) (map[string]interface{}, error) {
+	if limit <= 0 || limit > maxDeadLetterPage {
+		limit = maxDeadLetterPage
+	}
+
+	letters, err := s.deadLetters.List(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	return map[string]interface{}{
+		"count":        len(letters),
+		"dead_letters": letters,
+	}, nil
+}
+
+func (s *TrackingService) ReplayDeadLetters(ctx context.Context, orderNumber string) (map[string]interface{}, error) {
+	replayed, err := s.deadLetters.Replay(ctx, orderNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay dead letters: %w", err)
+	}
+
+	for _, letter := range replayed {
+		logger.Log(logger.INFO, "tracking-service", "dead_letter_replayed", "dead-lettered message replayed", "",
+			map[string]interface{}{
+				"order_number": letter.OrderNumber,
+				"queue":        letter.Queue,
+				"attempts":     letter.Attempts,
+			}, nil)
+	}
+
+	return map[string]interface{}{
+		"replayed":     len(replayed),
+		"dead_letters": replayed,
+	}, nil
+}
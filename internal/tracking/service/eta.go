@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restaurant-system/pkg/orderstatus"
+)
+
+const (
+	// etaHistory is how far back stage timings are used.
+	etaHistory = 7 * 24 * time.Hour
+	// etaMinSamples is the fewest timings an estimate is based on before
+	// widening from the order's type to all types, then to the defaults.
+	etaMinSamples = 5
+)
+
+// durationRange is the 10th, 50th and 90th percentile of a stage duration in
+// seconds. The estimate is the median and the range spans p10 to p90.
+type durationRange struct {
+	p10, p50, p90 float64
+	samples       int
+}
+
+// defaultRange is used while there is too little history. It keeps the old
+// fixed ten-minute guess as the median.
+func defaultRange(elapsed float64) durationRange {
+	return durationRange{
+		p10: max(300, elapsed+60),
+		p50: max(600, elapsed+120),
+		p90: max(900, elapsed+300),
+	}
+}
+
+func (d durationRange) remaining(elapsed float64) durationRange {
+	return durationRange{
+		p10:     max(d.p10-elapsed, 0),
+		p50:     max(d.p50-elapsed, 0),
+		p90:     max(d.p90-elapsed, 0),
+		samples: d.samples,
+	}
+}
+
+// stageDurations returns how long the stage took for recent orders that were
+// still in it after elapsed seconds, so the estimate for an order already in
+// the stage only looks at orders that took at least as long.
+func (s *TrackingService) stageDurations(ctx context.Context, stage, orderType string, elapsed float64) (durationRange, error) {
+	query := `
+		SELECT COUNT(*),
+			   COALESCE(percentile_cont(0.1) WITHIN GROUP (ORDER BY duration_seconds), 0),
+			   COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY duration_seconds), 0),
+			   COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY duration_seconds), 0)
+		FROM order_stage_timings
+		WHERE stage = $1
+		  AND ($2 = '' OR order_type = $2)
+		  AND duration_seconds > $3
+		  AND ended_at >= NOW() - make_interval(secs => $4)
+	`
+
+	for _, t := range []string{orderType, ""} {
+		var d durationRange
+		err := s.db.QueryRow(ctx, query, stage, t, elapsed, etaHistory.Seconds()).Scan(&d.samples, &d.p10, &d.p50, &d.p90)
+		if err != nil {
+			return durationRange{}, fmt.Errorf("failed to query stage durations: %w", err)
+		}
+		if d.samples >= etaMinSamples {
+			return d, nil
+		}
+	}
+
+	return defaultRange(elapsed), nil
+}
+
+// estimateCompletion predicts when a received or cooking order will be
+// ready. A cooking order needs the rest of its cook time. A received order
+// also waits for the orders ahead of it to be shared out over the cooking
+// slots of the online workers that take its type. With no such worker the
+// historical queue wait is used instead.
+func (s *TrackingService) estimateCompletion(ctx context.Context, orderID int, status, orderType string, priority int, createdAt time.Time) (map[string]interface{}, error) {
+	var enteredAt time.Time
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(MAX(changed_at), $2)
+		FROM order_status_log
+		WHERE order_id = $1 AND status = $3
+	`, orderID, createdAt, status).Scan(&enteredAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status start: %w", err)
+	}
+
+	now := time.Now()
+	elapsed := max(now.Sub(enteredAt).Seconds(), 0)
+	basis := map[string]interface{}{}
+
+	var total durationRange
+	switch status {
+	case orderstatus.Cooking:
+		cook, err := s.stageDurations(ctx, orderstatus.StageCook, orderType, elapsed)
+		if err != nil {
+			return nil, err
+		}
+		total = cook.remaining(elapsed)
+		basis["samples"] = cook.samples
+
+	case orderstatus.Received:
+		cook, err := s.stageDurations(ctx, orderstatus.StageCook, orderType, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only orders of the same type compete for the queue and the
+		// workers counted here.
+		var ahead, cooking, workers, slots int
+		err = s.db.QueryRow(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM orders
+				 WHERE status = 'received' AND type = $4 AND id <> $1
+				   AND (priority > $2 OR (priority = $2 AND created_at < $3))),
+				(SELECT COUNT(*) FROM orders WHERE status = 'cooking' AND type = $4),
+				COUNT(*), COALESCE(SUM(max_concurrency), 0)
+			FROM workers
+			WHERE status = 'online' AND NOW() - last_seen <= INTERVAL '60 seconds'
+			  AND (cardinality(order_types) = 0 OR $4 = ANY(order_types))
+		`, orderID, priority, createdAt, orderType).Scan(&ahead, &cooking, &workers, &slots)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kitchen load: %w", err)
+		}
+
+		var wait durationRange
+		if slots > 0 {
+			// Orders on the stove are on average half done.
+			turns := (float64(ahead) + float64(cooking)/2) / float64(slots)
+			wait = durationRange{p10: turns * cook.p10, p50: turns * cook.p50, p90: turns * cook.p90}
+		} else {
+			history, err := s.stageDurations(ctx, orderstatus.StageQueueWait, orderType, elapsed)
+			if err != nil {
+				return nil, err
+			}
+			wait = history.remaining(elapsed)
+		}
+
+		total = durationRange{
+			p10:     wait.p10 + cook.p10,
+			p50:     wait.p50 + cook.p50,
+			p90:     wait.p90 + cook.p90,
+			samples: cook.samples,
+		}
+		basis["samples"] = cook.samples
+		basis["queue_position"] = ahead + 1
+		basis["online_workers"] = workers
+		basis["cooking_slots"] = slots
+
+	default:
+		return nil, nil
+	}
+
+	at := func(seconds float64) string {
+		return now.Add(time.Duration(seconds * float64(time.Second))).UTC().Format(time.RFC3339)
+	}
+
+	return map[string]interface{}{
+		"estimated_completion": at(total.p50),
+		"estimate_range": map[string]interface{}{
+			"earliest": at(total.p10),
+			"latest":   at(total.p90),
+		},
+		"estimate_basis": basis,
+	}, nil
+}
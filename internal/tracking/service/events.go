@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"restaurant-system/internal/tracking/infrastructure/rmq"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var OrderNotFoundError = errors.New("order not found")
+
+// OrderEvent is one entry of order_status_log. Its ID is the SSE event id,
+// so a client that reconnects can resume right after the last one it saw.
+type OrderEvent struct {
+	ID          int       `json:"id"`
+	OrderNumber string    `json:"order_number"`
+	Status      string    `json:"status"`
+	ChangedBy   string    `json:"changed_by"`
+	ChangedAt   time.Time `json:"changed_at"`
+	Notes       *string   `json:"notes,omitempty"`
+}
+
+// eventHub wakes the streams watching an order whenever a status change for
+// it arrives on notifications_fanout. Streams read the changes themselves
+// from order_status_log, so a wake-up carries no data and several can be
+// merged into one.
+type eventHub struct {
+	mu     sync.Mutex
+	subs   map[string]map[chan struct{}]struct{}
+	closed bool
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[string]map[chan struct{}]struct{})}
+}
+
+func (h *eventHub) subscribe(orderNumber string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if h.subs[orderNumber] == nil {
+		h.subs[orderNumber] = make(map[chan struct{}]struct{})
+	}
+	h.subs[orderNumber][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[orderNumber][ch]; !ok {
+			return
+		}
+		delete(h.subs[orderNumber], ch)
+		if len(h.subs[orderNumber]) == 0 {
+			delete(h.subs, orderNumber)
+		}
+	}
+}
+
+func (h *eventHub) notify(orderNumber string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[orderNumber] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// close ends every stream; it is called when the service shuts down.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for orderNumber, subs := range h.subs {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(h.subs, orderNumber)
+	}
+}
+
+// RunEvents forwards status changes to the order streams and the kitchen
+// dashboards until updates is closed, then closes them.
+func (s *TrackingService) RunEvents(updates <-chan *rmq.StatusUpdate) {
+	defer s.dashboard.close()
+	defer s.events.close()
+	for update := range updates {
+		s.events.notify(update.OrderNumber)
+		s.dashboard.publish(update)
+	}
+}
+
+// SubscribeOrder returns a channel that receives a value whenever the order
+// may have new events, and is closed when the service stops.
+func (s *TrackingService) SubscribeOrder(orderNumber string) (<-chan struct{}, func()) {
+	return s.events.subscribe(orderNumber)
+}
+
+// GetOrderSnapshot returns the order's current status together with the id
+// of its latest status log entry.
+func (s *TrackingService) GetOrderSnapshot(ctx context.Context, orderNumber string) (map[string]interface{}, int, error) {
+	var lastID int
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(MAX(l.id), 0)
+		FROM orders o
+		LEFT JOIN order_status_log l ON l.order_id = o.id
+		WHERE o.number = $1
+		GROUP BY o.id
+	`, orderNumber).Scan(&lastID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, OrderNotFoundError
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get latest order event: %w", err)
+	}
+
+	status, err := s.GetOrderStatus(ctx, orderNumber)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return status, lastID, nil
+}
+
+// GetOrderEvents returns the order's status log entries after afterID.
+func (s *TrackingService) GetOrderEvents(ctx context.Context, orderNumber string, afterID int) ([]OrderEvent, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT l.id, o.number, COALESCE(l.status, ''), COALESCE(l.changed_by, ''), COALESCE(l.changed_at, l.created_at), l.notes
+		FROM order_status_log l
+		JOIN orders o ON o.id = l.order_id
+		WHERE o.number = $1 AND l.id > $2
+		ORDER BY l.id
+	`, orderNumber, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []OrderEvent{}
+	for rows.Next() {
+		var e OrderEvent
+		if err := rows.Scan(&e.ID, &e.OrderNumber, &e.Status, &e.ChangedBy, &e.ChangedAt, &e.Notes); err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"restaurant-system/pkg/logger"
+)
+
+var (
+	InvalidShiftError  = errors.New("invalid shift")
+	ShiftNotFoundError = errors.New("shift not found")
+)
+
+type ShiftRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Stations []string  `json:"stations"`
+}
+
+func (r *ShiftRequest) Validate() error {
+	if r.StartsAt.IsZero() || r.EndsAt.IsZero() {
+		return fmt.Errorf("%w: starts_at and ends_at are required", InvalidShiftError)
+	}
+	if !r.EndsAt.After(r.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", InvalidShiftError)
+	}
+	if r.EndsAt.Sub(r.StartsAt) > 24*time.Hour {
+		return fmt.Errorf("%w: shifts cannot be longer than 24 hours", InvalidShiftError)
+	}
+	return nil
+}
+
+func (s *TrackingService) CreateShift(ctx context.Context, workerName string, req *ShiftRequest) (map[string]interface{}, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Stations == nil {
+		req.Stations = []string{}
+	}
+
+	var id int
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO worker_shifts (worker_name, starts_at, ends_at, stations)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, workerName, req.StartsAt, req.EndsAt, req.Stations).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shift: %w", err)
+	}
+
+	logger.Log(logger.INFO, "tracking-service", "shift_created", "worker shift scheduled", "",
+		map[string]interface{}{"worker_name": workerName, "shift_id": id}, nil)
+
+	return map[string]interface{}{
+		"id":          id,
+		"worker_name": workerName,
+		"starts_at":   req.StartsAt.UTC().Format(time.RFC3339),
+		"ends_at":     req.EndsAt.UTC().Format(time.RFC3339),
+		"stations":    req.Stations,
+	}, nil
+}
+
+// GetShifts lists the worker's shifts that have not ended yet.
+func (s *TrackingService) GetShifts(ctx context.Context, workerName string) ([]map[string]interface{}, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, starts_at, ends_at, stations
+		FROM worker_shifts
+		WHERE worker_name = $1 AND ends_at > NOW()
+		ORDER BY starts_at
+	`, workerName)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query worker shifts", "",
+			map[string]interface{}{"worker_name": workerName}, err)
+		return nil, fmt.Errorf("failed to get worker shifts")
+	}
+	defer rows.Close()
+
+	shifts := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var id int
+		var startsAt, endsAt time.Time
+		var stations []string
+		if err := rows.Scan(&id, &startsAt, &endsAt, &stations); err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, map[string]interface{}{
+			"id":          id,
+			"worker_name": workerName,
+			"starts_at":   startsAt.Format(time.RFC3339),
+			"ends_at":     endsAt.Format(time.RFC3339),
+			"stations":    stations,
+		})
+	}
+
+	return shifts, rows.Err()
+}
+
+func (s *TrackingService) DeleteShift(ctx context.Context, workerName string, shiftID int) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM worker_shifts WHERE id = $1 AND worker_name = $2`, shiftID, workerName)
+	if err != nil {
+		return fmt.Errorf("failed to delete shift: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ShiftNotFoundError
+	}
+
+	logger.Log(logger.INFO, "tracking-service", "shift_deleted", "worker shift removed", "",
+		map[string]interface{}{"worker_name": workerName, "shift_id": shiftID}, nil)
+
+	return nil
+}
+
+// GetStaffing compares the workers scheduled right now with the workers
+// actually sending heartbeats, overall and per station.
+func (s *TrackingService) GetStaffing(ctx context.Context) (map[string]interface{}, error) {
+	rows, err := s.db.Query(ctx, `
+		WITH scheduled AS (
+			SELECT DISTINCT ON (worker_name) worker_name, starts_at, ends_at, stations
+			FROM worker_shifts
+			WHERE starts_at <= NOW() AND ends_at > NOW()
+			ORDER BY worker_name, starts_at
+		), active AS (
+			SELECT name, status, stations
+			FROM workers
+			WHERE status <> 'offline' AND NOW() - last_seen <= INTERVAL '60 seconds'
+		)
+		SELECT COALESCE(s.worker_name, a.name), s.starts_at, s.ends_at, s.stations, a.status, a.stations
+		FROM scheduled s
+		FULL OUTER JOIN active a ON a.name = s.worker_name
+		ORDER BY 1
+	`)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query staffing", "", nil, err)
+		return nil, fmt.Errorf("failed to get staffing")
+	}
+	defer rows.Close()
+
+	workers := make([]map[string]interface{}, 0)
+	missing := make([]string, 0)
+	unscheduled := make([]string, 0)
+	expected, actual := 0, 0
+	expectedByStation := make(map[string]int)
+	actualByStation := make(map[string]int)
+
+	for rows.Next() {
+		var name string
+		var startsAt, endsAt *time.Time
+		var shiftStations, workerStations []string
+		var status *string
+		if err := rows.Scan(&name, &startsAt, &endsAt, &shiftStations, &status, &workerStations); err != nil {
+			return nil, err
+		}
+
+		worker := map[string]interface{}{
+			"worker_name": name,
+			"scheduled":   startsAt != nil,
+			"online":      status != nil,
+		}
+		if startsAt != nil {
+			expected++
+			worker["shift_start"] = startsAt.Format(time.RFC3339)
+			worker["shift_end"] = endsAt.Format(time.RFC3339)
+			worker["shift_stations"] = shiftStations
+			for _, station := range shiftStations {
+				expectedByStation[station]++
+			}
+			if status == nil {
+				missing = append(missing, name)
+			}
+		}
+		if status != nil {
+			actual++
+			worker["status"] = *status
+			for _, station := range workerStations {
+				actualByStation[station]++
+			}
+			if startsAt == nil {
+				unscheduled = append(unscheduled, name)
+			}
+		}
+		workers = append(workers, worker)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(expectedByStation))
+	for station := range expectedByStation {
+		names = append(names, station)
+	}
+	for station := range actualByStation {
+		if _, ok := expectedByStation[station]; !ok {
+			names = append(names, station)
+		}
+	}
+	sort.Strings(names)
+
+	stations := make([]map[string]interface{}, 0, len(names))
+	for _, station := range names {
+		stations = append(stations, map[string]interface{}{
+			"station":  station,
+			"expected": expectedByStation[station],
+			"actual":   actualByStation[station],
+		})
+	}
+
+	return map[string]interface{}{
+		"at":          time.Now().UTC().Format(time.RFC3339),
+		"expected":    expected,
+		"actual":      actual,
+		"missing":     missing,
+		"unscheduled": unscheduled,
+		"stations":    stations,
+		"workers":     workers,
+	}, nil
+}
@@ -5,42 +5,60 @@ import (
 	"fmt"
 	"time"
 
+	"restaurant-system/internal/tracking/infrastructure/rmq"
 	"restaurant-system/pkg/logger"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+type DeadLetterQueue interface {
+	List(ctx context.Context, limit int) ([]rmq.DeadLetter, error)
+	Replay(ctx context.Context, orderNumber string) ([]rmq.DeadLetter, error)
+}
+
 type TrackingService struct {
-	db *pgxpool.Pool
+	db          *pgxpool.Pool
+	deadLetters DeadLetterQueue
+	control     ControlPublisher
+	queues      QueueInspector
+	events      *eventHub
+	dashboard   *dashboardHub
 }
 
-func NewTrackingService(db *pgxpool.Pool) *TrackingService {
-	return &TrackingService{db: db}
+func NewTrackingService(db *pgxpool.Pool, deadLetters DeadLetterQueue, control ControlPublisher, queues QueueInspector) *TrackingService {
+	return &TrackingService{
+		db:          db,
+		deadLetters: deadLetters,
+		control:     control,
+		queues:      queues,
+		events:      newEventHub(),
+		dashboard:   newDashboardHub(),
+	}
 }
 
 func (s *TrackingService) GetOrderStatus(ctx context.Context, orderNumber string) (map[string]interface{}, error) {
 	query := `
-		SELECT number, status, updated_at, processed_by,
-			   CASE 
-				   WHEN status = 'cooking' THEN updated_at + INTERVAL '10 minutes'
-				   ELSE NULL 
-			   END as estimated_completion
+		SELECT id, number, status, type, priority, created_at, updated_at, processed_by
 		FROM orders 
 		WHERE number = $1
 	`
 
+	var orderID int
 	var orderNumberDB string
-	var status string
-	var updatedAt time.Time
+	var status, orderType string
+	var priority int
+	var createdAt, updatedAt time.Time
 	var processedBy *string
-	var estimatedCompletion *time.Time
 
 	err := s.db.QueryRow(ctx, query, orderNumber).Scan(
+		&orderID,
 		&orderNumberDB,
 		&status,
+		&orderType,
+		&priority,
+		&createdAt,
 		&updatedAt,
 		&processedBy,
-		&estimatedCompletion,
 	)
 	if err != nil {
 		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query order status", "",
@@ -55,8 +73,14 @@ func (s *TrackingService) GetOrderStatus(ctx context.Context, orderNumber string
 	if processedBy != nil {
 		result["processed_by"] = *processedBy
 	}
-	if estimatedCompletion != nil {
-		result["estimated_completion"] = estimatedCompletion.Format(time.RFC3339)
+
+	estimate, err := s.estimateCompletion(ctx, orderID, status, orderType, priority, createdAt)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "eta_estimate_failed", "failed to estimate completion", "",
+			map[string]interface{}{"order_number": orderNumber}, err)
+	}
+	for key, value := range estimate {
+		result[key] = value
 	}
 
 	return result, nil
@@ -104,7 +128,8 @@ func (s *TrackingService) GetOrderHistory(ctx context.Context, orderNumber strin
 
 func (s *TrackingService) GetWorkersStatus(ctx context.Context) ([]map[string]interface{}, error) {
 	query := `
-        SELECT name, status, orders_processed, last_seen,
+        SELECT name, type, status, orders_processed, last_seen, in_flight_orders,
+               order_types, stations, max_concurrency, version,
                CASE 
                    WHEN NOW() - last_seen > INTERVAL '60 seconds' THEN 'offline'
                    ELSE status
@@ -122,21 +147,34 @@ func (s *TrackingService) GetWorkersStatus(ctx context.Context) ([]map[string]in
 
 	workers := make([]map[string]interface{}, 0)
 	for rows.Next() {
-		var name, status string
+		var name, workerType, status string
 		var ordersProcessed int
 		var lastSeen time.Time
+		var inFlight, orderTypes, stations []string
+		var maxConcurrency int
+		var version *string
 		var currentStatus string
 
-		if err := rows.Scan(&name, &status, &ordersProcessed, &lastSeen, &currentStatus); err != nil {
+		if err := rows.Scan(&name, &workerType, &status, &ordersProcessed, &lastSeen, &inFlight,
+			&orderTypes, &stations, &maxConcurrency, &version, &currentStatus); err != nil {
 			return []map[string]interface{}{}, err
 		}
 
-		workers = append(workers, map[string]interface{}{
+		worker := map[string]interface{}{
 			"worker_name":      name,
+			"worker_type":      workerType,
 			"status":           currentStatus,
 			"orders_processed": ordersProcessed,
 			"last_seen":        lastSeen.Format(time.RFC3339),
-		})
+			"in_flight_orders": inFlight,
+			"order_types":      orderTypes,
+			"stations":         stations,
+			"max_concurrency":  maxConcurrency,
+		}
+		if version != nil {
+			worker["version"] = *version
+		}
+		workers = append(workers, worker)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -145,3 +183,82 @@ func (s *TrackingService) GetWorkersStatus(ctx context.Context) ([]map[string]in
 
 	return workers, nil
 }
+
+func (s *TrackingService) GetFeedbackSummary(ctx context.Context) (map[string]interface{}, error) {
+	workerQuery := `
+		SELECT COALESCE(processed_by, 'unknown'), COUNT(*), AVG(rating)::float8,
+			   COUNT(*) FILTER (WHERE rating <= 2)
+		FROM order_feedback
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := s.db.Query(ctx, workerQuery)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query worker feedback", "", nil, err)
+		return nil, fmt.Errorf("failed to get feedback summary")
+	}
+
+	workers := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var name string
+		var count, lowRatings int
+		var average float64
+
+		if err := rows.Scan(&name, &count, &average, &lowRatings); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		workers = append(workers, map[string]interface{}{
+			"worker_name":    name,
+			"ratings":        count,
+			"average_rating": average,
+			"low_ratings":    lowRatings,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	itemQuery := `
+		SELECT oi.name, COUNT(DISTINCT f.order_id), AVG(f.rating)::float8
+		FROM order_feedback f
+		JOIN order_items oi ON oi.order_id = f.order_id
+		GROUP BY oi.name
+		ORDER BY oi.name
+	`
+
+	rows, err = s.db.Query(ctx, itemQuery)
+	if err != nil {
+		logger.Log(logger.ERROR, "tracking-service", "db_query_failed", "failed to query item feedback", "", nil, err)
+		return nil, fmt.Errorf("failed to get feedback summary")
+	}
+	defer rows.Close()
+
+	items := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var name string
+		var count int
+		var average float64
+
+		if err := rows.Scan(&name, &count, &average); err != nil {
+			return nil, err
+		}
+
+		items = append(items, map[string]interface{}{
+			"item_name":      name,
+			"ratings":        count,
+			"average_rating": average,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"workers": workers,
+		"items":   items,
+	}, nil
+}
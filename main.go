@@ -29,8 +29,16 @@ func main() {
 	maxConcurrent := flag.Int("max-concurrent", 10, "Maximum number of concurrent requests")
 	workerName := flag.String("worker-name", "", "Unique kitchen worker name")
 	orderTypes := flag.String("order-types", "", "Comma-separated list of order types for this worker")
+	stations := flag.String("stations", "", "Comma-separated list of kitchen stations for this worker")
 	prefetch := flag.Int("prefetch", 1, "RabbitMQ prefetch count")
+	cookingSlots := flag.Int("cooking-slots", 1, "Number of orders a kitchen worker cooks in parallel")
 	heartbeat := flag.Int("heartbeat-interval", 30, "Heartbeat interval in seconds")
+	bumpMode := flag.String("bump-mode", "auto", "Kitchen bump mode: auto (simulated) or manual (staff bump via HTTP)")
+	bumpPort := flag.Int("bump-port", 3100, "HTTP port for the manual bump API")
+	bumpTimeout := flag.Int("bump-timeout", 900, "Seconds before an unbumped order is escalated")
+	batchSize := flag.Int("batch-size", 1, "Orders a kitchen worker cooks together in one batch (1 disables batching)")
+	batchWindow := flag.Int("batch-window", 3, "Seconds a kitchen worker waits to fill a batch")
+	drainTimeout := flag.Int("drain-timeout", 30, "Seconds a stopping kitchen worker waits for in-flight orders")
 	trackingPort := flag.Int("tracking-port", 3002, "HTTP port for tracking service")
 	configPath := flag.String("config", "config/config.yaml", "Path to config file")
 
@@ -77,22 +85,30 @@ func main() {
 
 	switch *mode {
 	case "order-service":
-		order.Run(ctx, pg.Pool, rmq, *orderPort, *maxConcurrent, requestID)
+		order.Run(ctx, pg.Pool, rmq, cfg.Admission, cfg.PickupSlots, cfg.Loyalty, cfg.Reaper, cfg.Aging, *orderPort, *maxConcurrent, requestID)
 	case "kitchen-worker":
 		if *workerName == "" {
 			fmt.Println("Error: --worker-name is required for kitchen-worker")
 			os.Exit(1)
 		}
-		var typesList []string
-		if *orderTypes != "" {
-			typesList = strings.Split(*orderTypes, ",")
-			for i := range typesList {
-				typesList[i] = strings.TrimSpace(typesList[i])
-			}
+		if *bumpMode != "auto" && *bumpMode != "manual" {
+			fmt.Println("Error: --bump-mode must be auto or manual")
+			os.Exit(1)
+		}
+		bump := kitchen.BumpOptions{
+			Mode:    *bumpMode,
+			Port:    *bumpPort,
+			Timeout: time.Duration(*bumpTimeout) * time.Second,
 		}
-		kitchen.Run(ctx, pg.Pool, rmq, *workerName, typesList, *prefetch, *heartbeat, requestID)
+		batch := kitchen.BatchOptions{
+			Size:   *batchSize,
+			Window: time.Duration(*batchWindow) * time.Second,
+		}
+		kitchen.Run(ctx, pg.Pool, rmq, cfg.Kitchen, *workerName, splitList(*orderTypes), splitList(*stations), *prefetch, *cookingSlots, *heartbeat, bump, batch, time.Duration(*drainTimeout)*time.Second, requestID)
+		// The worker handles its own termination signal and returns once drained.
+		return
 	case "tracking-service":
-		tracking.Run(ctx, pg.Pool, rmq, *trackingPort, requestID)
+		tracking.Run(ctx, pg.Pool, rmq, cfg.Kitchen, *trackingPort, requestID)
 	case "notification-subscriber":
 		notification.Run(ctx, rmq, requestID)
 	default:
@@ -107,3 +123,14 @@ func main() {
 	cancel()
 	time.Sleep(1 * time.Second)
 }
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	list := strings.Split(value, ",")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list
+}
@@ -0,0 +1,14 @@
+create table pickup_slots (
+                              "id"             serial       primary key,
+                              "created_at"     timestamptz  not null    default now(),
+                              "slot_start"     timestamptz  unique not null,
+                              "slot_end"       timestamptz  not null,
+                              "max_orders"     integer      not null,
+                              "max_items"      integer      not null,
+                              "booked_orders"  integer      not null    default 0,
+                              "booked_items"   integer      not null    default 0,
+                              check (booked_orders <= max_orders),
+                              check (booked_items <= max_items)
+);
+
+alter table orders add column "pickup_slot_id" integer references pickup_slots(id);
@@ -0,0 +1,15 @@
+alter table orders add column "loyalty_id" text;
+alter table orders add column "discount_amount" decimal(10,2) not null default 0;
+
+create table loyalty_ledger (
+                                "id"          serial       primary key,
+                                "created_at"  timestamptz  not null    default now(),
+                                "loyalty_id"  text         not null,
+                                "order_id"    integer      references orders(id),
+                                "entry_type"  text         not null check (entry_type in ('earn', 'redeem', 'reverse_earn', 'reverse_redeem')),
+                                "points"      integer      not null,
+                                "notes"       text,
+                                unique (order_id, entry_type)
+);
+
+create index loyalty_ledger_loyalty_id_idx on loyalty_ledger (loyalty_id);
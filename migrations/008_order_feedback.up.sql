@@ -0,0 +1,10 @@
+create table order_feedback (
+                                "id"            serial       primary key,
+                                "created_at"    timestamptz  not null    default now(),
+                                "order_id"      integer      unique not null references orders(id),
+                                "rating"        integer      not null check (rating between 1 and 5),
+                                "comment"       text,
+                                "processed_by"  text
+);
+
+create index order_feedback_processed_by_idx on order_feedback (processed_by);
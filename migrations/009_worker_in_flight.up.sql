@@ -0,0 +1 @@
+alter table workers add column "in_flight_orders" text[] not null default '{}';
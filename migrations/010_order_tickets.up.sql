@@ -0,0 +1,12 @@
+create table order_tickets (
+                               "id"            serial       primary key,
+                               "created_at"    timestamptz  not null    default now(),
+                               "order_id"      integer      not null references orders(id),
+                               "station"       text         not null,
+                               "items"         jsonb        not null,
+                               "status"        text         not null    default 'pending' check (status in ('pending', 'cooking', 'done')),
+                               "processed_by"  text,
+                               "completed_at"  timestamptz
+);
+
+create index order_tickets_order_id_idx on order_tickets (order_id);
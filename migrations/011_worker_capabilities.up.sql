@@ -0,0 +1,4 @@
+alter table workers add column "order_types"     text[]  not null default '{}';
+alter table workers add column "stations"        text[]  not null default '{}';
+alter table workers add column "max_concurrency" integer not null default 1;
+alter table workers add column "version"         text;
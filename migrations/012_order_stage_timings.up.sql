@@ -0,0 +1,13 @@
+create table order_stage_timings (
+                                     "id"                serial           primary key,
+                                     "order_id"          integer          not null references orders(id),
+                                     "stage"             text             not null check (stage in ('queue_wait', 'cook', 'pickup_wait')),
+                                     "worker_name"       text,
+                                     "order_type"        text             not null,
+                                     "started_at"        timestamptz      not null,
+                                     "ended_at"          timestamptz      not null,
+                                     "duration_seconds"  double precision not null,
+                                     unique (order_id, stage)
+);
+
+create index order_stage_timings_ended_at_idx on order_stage_timings (ended_at);
@@ -0,0 +1,54 @@
+create table ingredients (
+                             "id"          serial        primary key,
+                             "created_at"  timestamptz   not null    default now(),
+                             "updated_at"  timestamptz   not null    default now(),
+                             "name"        text          unique not null,
+                             "unit"        text          not null,
+                             "stock"       numeric(12,3) not null    default 0 check (stock >= 0),
+                             "reserved"    numeric(12,3) not null    default 0 check (reserved >= 0 and reserved <= stock)
+);
+
+-- item_name is the lowercase menu item name.
+create table recipes (
+                         "item_name"      text          not null,
+                         "ingredient_id"  integer       not null references ingredients(id),
+                         "quantity"       numeric(12,3) not null check (quantity > 0),
+                         primary key (item_name, ingredient_id)
+);
+
+create table stock_reservations (
+                                    "id"             serial        primary key,
+                                    "created_at"     timestamptz   not null    default now(),
+                                    "order_id"       integer       not null references orders(id),
+                                    "ingredient_id"  integer       not null references ingredients(id),
+                                    "quantity"       numeric(12,3) not null,
+                                    "status"         text          not null    default 'reserved' check (status in ('reserved', 'consumed', 'released')),
+                                    unique (order_id, ingredient_id)
+);
+
+insert into ingredients (name, unit, stock) values
+    ('pizza dough',     'portion', 40),
+    ('tomato sauce',    'g',       5000),
+    ('mozzarella',      'g',       6000),
+    ('pepperoni',       'g',       2000),
+    ('romaine',         'g',       3000),
+    ('caesar dressing', 'ml',      2000),
+    ('baguette',        'piece',   30),
+    ('garlic butter',   'g',       1000);
+
+insert into recipes (item_name, ingredient_id, quantity)
+select r.item_name, i.id, r.quantity
+from (values
+    ('margherita pizza', 'pizza dough',     1),
+    ('margherita pizza', 'tomato sauce',    100),
+    ('margherita pizza', 'mozzarella',      120),
+    ('pepperoni pizza',  'pizza dough',     1),
+    ('pepperoni pizza',  'tomato sauce',    100),
+    ('pepperoni pizza',  'mozzarella',      100),
+    ('pepperoni pizza',  'pepperoni',       60),
+    ('caesar salad',     'romaine',         150),
+    ('caesar salad',     'caesar dressing', 40),
+    ('garlic bread',     'baguette',        1),
+    ('garlic bread',     'garlic butter',   30)
+) as r(item_name, ingredient_name, quantity)
+join ingredients i on i.name = r.ingredient_name;
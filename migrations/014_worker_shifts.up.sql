@@ -0,0 +1,12 @@
+create table worker_shifts (
+                               "id"           serial       primary key,
+                               "created_at"   timestamptz  not null    default now(),
+                               "worker_name"  text         not null,
+                               "starts_at"    timestamptz  not null,
+                               "ends_at"      timestamptz  not null,
+                               "stations"     text[]       not null    default '{}',
+                               check (ends_at > starts_at)
+);
+
+create index worker_shifts_worker_name_idx on worker_shifts (worker_name, starts_at);
+create index worker_shifts_window_idx on worker_shifts (starts_at, ends_at);
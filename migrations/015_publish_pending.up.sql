@@ -0,0 +1,2 @@
+alter table orders add column "publish_pending" boolean not null default false;
+alter table order_tickets add column "publish_pending" boolean not null default false;
@@ -0,0 +1,2 @@
+alter table workers add column "started_at" timestamptz not null default now();
+alter table order_tickets add column "claimed_at" timestamptz;
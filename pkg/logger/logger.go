@@ -13,6 +13,7 @@ type LogLevel string
 const (
 	INFO  LogLevel = "INFO"
 	DEBUG LogLevel = "DEBUG"
+	WARN  LogLevel = "WARN"
 	ERROR LogLevel = "ERROR"
 )
 
@@ -0,0 +1,51 @@
+package orderstatus
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	Waitlisted = "waitlisted"
+	Received   = "received"
+	Cooking    = "cooking"
+	Ready      = "ready"
+	Completed  = "completed"
+	Cancelled  = "cancelled"
+	Refunded   = "refunded"
+)
+
+var (
+	InvalidTransitionError = errors.New("invalid order status transition")
+	StaleStatusError       = errors.New("order status changed concurrently")
+)
+
+// transitions is the single source of truth for order status changes.
+// cooking -> received is used when a worker gives an order back to the queue.
+var transitions = map[string][]string{
+	Waitlisted: {Received, Cancelled},
+	Received:   {Cooking, Cancelled},
+	Cooking:    {Ready, Received, Cancelled},
+	Ready:      {Completed, Cancelled},
+	Completed:  {Refunded},
+}
+
+func CanTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func Validate(from, to string) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", InvalidTransitionError, from, to)
+	}
+	return nil
+}
+
+func IsTerminal(status string) bool {
+	return len(transitions[status]) == 0
+}
@@ -0,0 +1,70 @@
+package orderstatus
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{Waitlisted, Received, true},
+		{Waitlisted, Cancelled, true},
+		{Waitlisted, Cooking, false},
+		{Received, Cooking, true},
+		{Received, Cancelled, true},
+		{Received, Ready, false},
+		{Received, Waitlisted, false},
+		{Cooking, Ready, true},
+		{Cooking, Received, true},
+		{Cooking, Cancelled, true},
+		{Cooking, Completed, false},
+		{Ready, Completed, true},
+		{Ready, Cancelled, true},
+		{Ready, Cooking, false},
+		{Completed, Refunded, true},
+		{Completed, Cancelled, false},
+		{Cancelled, Received, false},
+		{Refunded, Completed, false},
+		{"unknown", Received, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.allowed {
+				t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.allowed)
+			}
+
+			err := Validate(tt.from, tt.to)
+			if tt.allowed && err != nil {
+				t.Errorf("Validate(%q, %q) = %v, want nil", tt.from, tt.to, err)
+			}
+			if !tt.allowed && !errors.Is(err, InvalidTransitionError) {
+				t.Errorf("Validate(%q, %q) = %v, want InvalidTransitionError", tt.from, tt.to, err)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	tests := []struct {
+		status   string
+		terminal bool
+	}{
+		{Waitlisted, false},
+		{Received, false},
+		{Cooking, false},
+		{Ready, false},
+		{Completed, false},
+		{Cancelled, true},
+		{Refunded, true},
+	}
+
+	for _, tt := range tests {
+		if got := IsTerminal(tt.status); got != tt.terminal {
+			t.Errorf("IsTerminal(%q) = %v, want %v", tt.status, got, tt.terminal)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package orderstatus
+
+const (
+	StageQueueWait  = "queue_wait"
+	StageCook       = "cook"
+	StagePickupWait = "pickup_wait"
+)
+
+// stages maps the transition that ends a timed stage to that stage. The
+// stage starts at the latest status log entry for the transition's source.
+var stages = map[[2]string]string{
+	{Received, Cooking}: StageQueueWait,
+	{Cooking, Ready}:    StageCook,
+	{Ready, Completed}:  StagePickupWait,
+}
+
+// Stage returns the timed stage that a transition completes, if any.
+func Stage(from, to string) (string, bool) {
+	stage, ok := stages[[2]string{from, to}]
+	return stage, ok
+}
@@ -170,3 +170,44 @@ func (r *RabbitMQ) Consume(ctx context.Context, queue, consumer string, autoAck
 		nil,
 	)
 }
+
+// OpenChannel returns a dedicated channel for work that should not share
+// delivery tags or QoS with the main channel. The caller closes it.
+func (r *RabbitMQ) OpenChannel() (*amqp091.Channel, error) {
+	if r.conn.IsClosed() {
+		if err := r.Reconnect(); err != nil {
+			return nil, fmt.Errorf("failed to reconnect: %w", err)
+		}
+	}
+
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	return ch, nil
+}
+
+func (r *RabbitMQ) InspectQueue(name string) (amqp091.Queue, error) {
+	if r.conn.IsClosed() {
+		if err := r.Reconnect(); err != nil {
+			return amqp091.Queue{}, fmt.Errorf("failed to reconnect: %w", err)
+		}
+	}
+
+	// A passive declare of a missing queue closes the channel, so use a
+	// throwaway one instead of the shared channel.
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return amqp091.Queue{}, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	return ch.QueueDeclarePassive(
+		name,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+}
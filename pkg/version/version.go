@@ -0,0 +1,6 @@
+package version
+
+// Version is the build version, set at link time:
+//
+//	go build -ldflags "-X restaurant-system/pkg/version.Version=1.4.0"
+var Version = "dev"